@@ -1,11 +1,17 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
+	"os/signal"
+	"syscall"
 
 	"OpenEx-Backend/internal/config"
 	"OpenEx-Backend/internal/database"
 	"OpenEx-Backend/internal/routes"
+	"OpenEx-Backend/internal/scheduler"
 	"OpenEx-Backend/internal/worker"
 )
 
@@ -21,15 +27,62 @@ func main() {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
-	// Start auto-approver worker
-	go worker.StartAutoApprover()
+	// Cancelled on SIGINT or SIGTERM, which starts the shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Start background jobs
+	// Exclusive jobs take a MySQL lock, so they run once across all instances
+	jobs := scheduler.Default
+	jobs.SetLocker(database.AdvisoryLocker{})
+	jobs.Start(ctx)
+	if err := worker.StartAutoApprover(jobs); err != nil {
+		log.Fatalf("Failed to start auto-approver: %v", err)
+	}
+	if err := worker.StartExpiry(jobs); err != nil {
+		log.Fatalf("Failed to start expiry job: %v", err)
+	}
+	if err := worker.StartBookingReminders(jobs); err != nil {
+		log.Fatalf("Failed to start booking reminders: %v", err)
+	}
 
 	// Set up router with all routes
 	router := routes.SetupRouter()
+	server := &http.Server{
+		Addr:    ":" + cfg.ServerPort,
+		Handler: router,
+	}
 
 	// Start the server
-	log.Printf("Starting server on port %s", cfg.ServerPort)
-	if err := router.Run(":" + cfg.ServerPort); err != nil {
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Printf("Starting server on port %s", cfg.ServerPort)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	select {
+	case err := <-serverErr:
 		log.Fatalf("Failed to start server: %v", err)
+	case <-ctx.Done():
 	}
+	stop()
+
+	// Stop accepting requests, then let in-flight requests and jobs finish
+	log.Printf("Shutting down (waiting up to %v)", cfg.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Error shutting down server: %v", err)
+	}
+	if err := jobs.Stop(shutdownCtx); err != nil {
+		// Jobs still running would fail on a closed pool, so leave it to the process exit
+		log.Printf("Error stopping background jobs, leaving the database open: %v", err)
+	} else if err := database.Close(); err != nil {
+		log.Printf("Error closing database: %v", err)
+	}
+
+	log.Println("Server stopped")
 }
@@ -3,18 +3,22 @@ package config
 import (
     "fmt"
     "os"
+    "strconv"
+    "time"
 
     "github.com/joho/godotenv"
 )
 
 type Config struct {
-    DBUser     string
-    DBPassword string
-    DBHost     string
-    DBPort     string
-    DBName     string
-    JWTSecret  string
-    ServerPort string
+    DBUser          string
+    DBPassword      string
+    DBHost          string
+    DBPort          string
+    DBName          string
+    JWTSecret       string
+    ServerPort      string
+    // How long in-flight requests and background jobs get to finish on shutdown
+    ShutdownTimeout time.Duration
 }
 
 // Load loads configuration from environment variables
@@ -25,18 +29,40 @@ func Load() (*Config, error) {
     }
 
     config := &Config{
-        DBUser:     getEnv("DB_USER", ""),
-        DBPassword: getEnv("DB_PASSWORD", ""),
-        DBHost:     getEnv("DB_HOST", "localhost"),
-        DBPort:     getEnv("DB_PORT", "3306"),
-        DBName:     getEnv("DB_NAME", ""),
-        JWTSecret:  getEnv("JWT_SECRET", "your-secret-key"),
-        ServerPort: getEnv("SERVER_PORT", "8080"),
+        DBUser:          getEnv("DB_USER", ""),
+        DBPassword:      getEnv("DB_PASSWORD", ""),
+        DBHost:          getEnv("DB_HOST", "localhost"),
+        DBPort:          getEnv("DB_PORT", "3306"),
+        DBName:          getEnv("DB_NAME", ""),
+        JWTSecret:       getEnv("JWT_SECRET", "your-secret-key"),
+        ServerPort:      getEnv("SERVER_PORT", "8080"),
+        ShutdownTimeout: 30 * time.Second,
+    }
+
+    if seconds, err := strconv.Atoi(getEnv("SHUTDOWN_TIMEOUT_SECONDS", "")); err == nil && seconds > 0 {
+        config.ShutdownTimeout = time.Duration(seconds) * time.Second
     }
 
     return config, nil
 }
 
+// FrontendURL returns the base URL of the web app, used for links in emails
+func FrontendURL() string {
+    if url := os.Getenv("FRONTEND_URL"); url != "" {
+        return url
+    }
+    return "http://localhost:5173"
+}
+
+// BackendURL returns the public base URL of this API, used for links served to other apps
+// such as calendar subscriptions
+func BackendURL() string {
+    if url := os.Getenv("BACKEND_URL"); url != "" {
+        return url
+    }
+    return "http://localhost:8080"
+}
+
 // GetDSN returns the database connection string
 func (c *Config) GetDSN() string {
     return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
@@ -14,7 +14,8 @@ var DB *gorm.DB
 // Initialize sets up the database connection
 func Initialize(dsn string) error {
 	var err error
-	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	// TranslateError turns driver errors such as duplicate keys into gorm's sentinel errors
+	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		return err
 	}
@@ -30,6 +31,17 @@ func Initialize(dsn string) error {
 		&models.ServiceRequest{},
 		&models.Favorite{},
 		&models.PasswordReset{},
+		&models.EmailChange{},
+		&models.AuditLog{},
+		&models.ModerationResult{},
+		&models.ModerationProviderScore{},
+		&models.Report{},
+		&models.Appeal{},
+		&models.Offer{},
+		&models.Proposal{},
+		&models.ServiceSlot{},
+		&models.Booking{},
+		&models.CalendarFeed{},
 	)
 	if err != nil {
 		return err
@@ -48,3 +60,15 @@ func Initialize(dsn string) error {
 
 	return nil
 }
+
+// Close closes the underlying connection pool
+func Close() error {
+	if DB == nil {
+		return nil
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// lockPrefix namespaces advisory lock names, which are global to the MySQL server
+const lockPrefix = "openex:"
+
+// AdvisoryLocker takes MySQL named locks (GET_LOCK) so that a job only runs on one
+// instance at a time. Each lock lives on its own connection and is released by MySQL
+// if that connection or the instance dies.
+type AdvisoryLocker struct{}
+
+// TryLock takes the named lock without waiting. ok is false if another instance holds it.
+func (AdvisoryLocker) TryLock(ctx context.Context, name string) (unlock func(), ok bool, err error) {
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return nil, false, err
+	}
+
+	// GET_LOCK is bound to the session, so hold on to one connection until unlock
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	name = lockPrefix + name
+	var acquired sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 0)", name).Scan(&acquired); err != nil {
+		conn.Close()
+		return nil, false, err
+	}
+	if !acquired.Valid || acquired.Int64 != 1 {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	unlock = func() {
+		// The job's context may already be cancelled, so release with a fresh one
+		releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := conn.ExecContext(releaseCtx, "SELECT RELEASE_LOCK(?)", name); err != nil {
+			log.Printf("Error releasing lock %s: %v", name, err)
+		}
+		conn.Close()
+	}
+	return unlock, true, nil
+}
+
+// Claim marks up to limit rows of model matching scope as claimed for lease and returns the
+// claim token. Rows claimed by someone else are skipped until their lease runs out, so
+// instances working through the same backlog never process a row twice. The model needs
+// ClaimToken and ClaimedUntil columns.
+func Claim(ctx context.Context, model interface{}, limit int, lease time.Duration, scope func(*gorm.DB) *gorm.DB) (string, int64, error) {
+	token, err := claimToken()
+	if err != nil {
+		return "", 0, err
+	}
+
+	now := time.Now()
+	result := DB.WithContext(ctx).Model(model).
+		Scopes(scope).
+		Where("claimed_until IS NULL OR claimed_until < ?", now).
+		Order("id").
+		Limit(limit).
+		Updates(map[string]interface{}{
+			"claim_token":   token,
+			"claimed_until": now.Add(lease),
+		})
+	if result.Error != nil {
+		return "", 0, result.Error
+	}
+	return token, result.RowsAffected, nil
+}
+
+// ReleaseClaim clears a claim so the rows can be picked up again right away. Scopes narrow
+// down the rows released; the others stay claimed until their lease runs out.
+func ReleaseClaim(model interface{}, token string, scopes ...func(*gorm.DB) *gorm.DB) error {
+	if token == "" {
+		return errors.New("empty claim token")
+	}
+	return DB.Model(model).Scopes(scopes...).Where("claim_token = ?", token).Updates(map[string]interface{}{
+		"claim_token":   "",
+		"claimed_until": nil,
+	}).Error
+}
+
+// claimToken returns a random token identifying one claim
+func claimToken() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"OpenEx-Backend/internal/config"
+	"OpenEx-Backend/internal/database"
+	"OpenEx-Backend/internal/models"
+	"OpenEx-Backend/internal/services/email"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ChangePasswordRequest is the request payload for changing the password of a logged-in user
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
+// ChangeEmailRequest is the request payload for starting an email change
+type ChangeEmailRequest struct {
+	NewEmail        string `json:"new_email" binding:"required,email"`
+	CurrentPassword string `json:"current_password" binding:"required"`
+}
+
+// ConfirmEmailChangeRequest is the request payload for confirming an email change
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ChangePassword updates the authenticated user's password after verifying the current one
+func ChangePassword(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Current password is incorrect"})
+		return
+	}
+
+	if req.CurrentPassword == req.NewPassword {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "New password must be different from the current password"})
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	user.Password = string(hashedPassword)
+	if err := database.DB.Save(&user).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update password"})
+		return
+	}
+
+	// Any outstanding reset links were issued for the old password
+	database.DB.Where("user_id = ?", user.ID).Delete(&models.PasswordReset{})
+
+	go sendPasswordChangedEmail(user)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
+}
+
+// RequestEmailChange sends a verification link to the new address of the authenticated user
+func RequestEmailChange(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var req ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Current password is incorrect"})
+		return
+	}
+
+	newEmail := strings.TrimSpace(req.NewEmail)
+	if strings.EqualFold(newEmail, user.Email) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "New email must be different from the current email"})
+		return
+	}
+
+	// Check if email already exists
+	var count int64
+	database.DB.Model(&models.User{}).Where("email = ?", newEmail).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email already exists"})
+		return
+	}
+
+	token, err := generateToken(32)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate verification token"})
+		return
+	}
+
+	// Only the most recent email change request stays valid
+	database.DB.Where("user_id = ?", user.ID).Delete(&models.EmailChange{})
+
+	change := models.EmailChange{
+		UserID:    user.ID,
+		NewEmail:  newEmail,
+		Token:     token,
+		ExpiresAt: time.Now().Add(24 * time.Hour), // Token expires in 24 hours
+		Used:      false,
+	}
+
+	if err := database.DB.Create(&change).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create verification token"})
+		return
+	}
+
+	confirmURL := fmt.Sprintf("%s/confirm-email?token=%s", config.FrontendURL(), token)
+	if err := sendEmailChangeVerification(newEmail, confirmURL); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send verification email"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "A verification link has been sent to your new email address"})
+}
+
+// ConfirmEmailChange applies a pending email change once the new address is verified
+func ConfirmEmailChange(c *gin.Context) {
+	var req ConfirmEmailChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var change models.EmailChange
+	if err := database.DB.Where("token = ? AND used = ? AND expires_at > ?", req.Token, false, time.Now()).First(&change).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired token"})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, change.UserID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "User not found"})
+		return
+	}
+
+	// The address may have been taken since the change was requested
+	var count int64
+	database.DB.Model(&models.User{}).Where("email = ? AND id <> ?", change.NewEmail, user.ID).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email already exists"})
+		return
+	}
+
+	oldEmail := user.Email
+	user.Email = change.NewEmail
+	if err := database.DB.Save(&user).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update email"})
+		return
+	}
+
+	// Mark the token as used
+	change.Used = true
+	database.DB.Save(&change)
+
+	go sendEmailChangedNotice(oldEmail, user.Email)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email has been changed successfully", "email": user.Email})
+}
+
+// Helper function to tell a user their password was changed
+func sendPasswordChangedEmail(user models.User) {
+	subject := "Your OpenEx password was changed"
+	htmlContent := fmt.Sprintf(`
+<html>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto;">
+    <h2 style="color: #4a6ee0;">Password Changed</h2>
+    <p>Hi %s,</p>
+    <p>The password for your OpenEx account was changed on %s.</p>
+    <p>If you didn't make this change, reset your password immediately using the "Forgot password" link on the login page.</p>
+    <p style="color: #777; font-size: 0.8em;">This is an automated message from OpenEx.</p>
+</body>
+</html>
+`, user.Name, time.Now().Format("January 2, 2006 at 3:04 PM"))
+
+	if err := email.SendEmail(user.Email, subject, htmlContent); err != nil {
+		log.Printf("Error sending password change notice: %v", err)
+	}
+}
+
+// Helper function to send the verification link for a new email address
+func sendEmailChangeVerification(to, confirmURL string) error {
+	subject := "Confirm your new OpenEx email address"
+	htmlContent := fmt.Sprintf(`
+<html>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto;">
+    <h2 style="color: #4a6ee0;">Confirm Your New Email</h2>
+    <p>You've requested to use this address for your OpenEx account.</p>
+    <p>Click the link below to confirm the change:</p>
+    <p><a href="%s">Confirm Email</a></p>
+    <p>This link will expire in 24 hours.</p>
+    <p>If you didn't request this, please ignore this email.</p>
+</body>
+</html>
+`, confirmURL)
+
+	return email.SendEmail(to, subject, htmlContent)
+}
+
+// Helper function to notify the previous address that the account email was changed
+func sendEmailChangedNotice(oldEmail, newEmail string) {
+	subject := "Your OpenEx email address was changed"
+	htmlContent := fmt.Sprintf(`
+<html>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto;">
+    <h2 style="color: #4a6ee0;">Email Address Changed</h2>
+    <p>The email address for your OpenEx account was changed to <strong>%s</strong> on %s.</p>
+    <p>You will no longer receive OpenEx notifications at this address.</p>
+    <p>If you didn't make this change, please contact support right away.</p>
+    <p style="color: #777; font-size: 0.8em;">This is an automated message from OpenEx.</p>
+</body>
+</html>
+`, newEmail, time.Now().Format("January 2, 2006 at 3:04 PM"))
+
+	if err := email.SendEmail(oldEmail, subject, htmlContent); err != nil {
+		log.Printf("Error sending email change notice to %s: %v", oldEmail, err)
+	}
+}
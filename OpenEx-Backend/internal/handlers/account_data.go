@@ -0,0 +1,416 @@
+package handlers
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"OpenEx-Backend/internal/database"
+	"OpenEx-Backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// DeleteAccountRequest is the request payload for deleting the authenticated user's account
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// ExportUserData returns everything stored about the authenticated user as JSON or a ZIP archive
+func ExportUserData(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	export, err := collectUserData(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export user data"})
+		return
+	}
+
+	filename := fmt.Sprintf("openex-export-%d-%s", user.ID, time.Now().Format("20060102"))
+
+	if c.DefaultQuery("format", "json") != "zip" {
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, filename))
+		c.IndentedJSON(http.StatusOK, export)
+		return
+	}
+
+	// One JSON file per section so the archive is easy to browse
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.zip"`, filename))
+	c.Status(http.StatusOK)
+
+	archive := zip.NewWriter(c.Writer)
+	for _, section := range []string{"profile", "items", "transaction_requests", "requested_items", "services", "service_requests", "favorites", "appeals", "offers", "proposals", "bookings"} {
+		file, err := archive.Create(section + ".json")
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(export[section]); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+	if err := archive.Close(); err != nil {
+		c.Error(err)
+	}
+}
+
+// collectUserData gathers the personal data export for a user
+func collectUserData(userID uint) (gin.H, error) {
+	var user models.User
+	if err := database.DB.Preload("Hostel").First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+
+	var items []models.Item
+	database.DB.Where("user_id = ?", userID).Find(&items)
+
+	var transactionRequests []models.TransactionRequest
+	database.DB.Preload("Item").Where("buyer_id = ? OR seller_id = ?", userID, userID).Find(&transactionRequests)
+
+	var requestedItems []models.RequestedItem
+	database.DB.Where("buyer_id = ?", userID).Find(&requestedItems)
+
+	var services []models.Service
+	database.DB.Where("user_id = ?", userID).Find(&services)
+
+	var serviceRequests []models.ServiceRequest
+	database.DB.Where("requester_id = ? OR provider_id = ?", userID, userID).Find(&serviceRequests)
+
+	var favorites []models.Favorite
+	database.DB.Preload("Item").Where("user_id = ?", userID).Find(&favorites)
+
+	var appeals []models.Appeal
+	database.DB.Where("user_id = ?", userID).Find(&appeals)
+
+	var offers []models.Offer
+	database.DB.Preload("RequestedItem").Where("seller_id = ?", userID).Find(&offers)
+
+	var proposals []models.Proposal
+	database.DB.Preload("ServiceRequest").Where("provider_id = ?", userID).Find(&proposals)
+
+	var bookings []models.Booking
+	database.DB.Preload("Service").Where("requester_id = ? OR provider_id = ?", userID, userID).Find(&bookings)
+
+	exportedItems := []gin.H{}
+	for _, item := range items {
+		exportedItems = append(exportedItems, gin.H{
+			"id":          item.ID,
+			"title":       item.Title,
+			"description": item.Description,
+			"price":       item.Price,
+			"image":       item.Image,
+			"type":        item.Type,
+			"status":      item.Status,
+			"quantity":    item.Quantity,
+			"hostel_id":   item.HostelID,
+			"created_at":  item.CreatedAt,
+			"updated_at":  item.UpdatedAt,
+		})
+	}
+
+	exportedRequests := []gin.H{}
+	for _, request := range transactionRequests {
+		role := "buyer"
+		if request.SellerID == userID {
+			role = "seller"
+		}
+		exportedRequests = append(exportedRequests, gin.H{
+			"id":              request.ID,
+			"role":            role,
+			"item_id":         request.ItemID,
+			"item_title":      request.Item.Title,
+			"offered_item_id": request.OfferedItemID,
+			"type":            request.Type,
+			"status":          request.Status,
+			"quantity":        request.Quantity,
+			"created_at":      request.CreatedAt,
+			"updated_at":      request.UpdatedAt,
+		})
+	}
+
+	exportedRequestedItems := []gin.H{}
+	for _, item := range requestedItems {
+		exportedRequestedItems = append(exportedRequestedItems, gin.H{
+			"id":                 item.ID,
+			"title":              item.Title,
+			"description":        item.Description,
+			"max_price":          item.MaxPrice,
+			"quantity":           item.Quantity,
+			"fulfilled_quantity": item.FulfilledQuantity,
+			"status":             item.Status,
+			"hostel_id":          item.HostelID,
+			"created_at":         item.CreatedAt,
+			"updated_at":         item.UpdatedAt,
+		})
+	}
+
+	exportedServices := []gin.H{}
+	for _, service := range services {
+		exportedServices = append(exportedServices, gin.H{
+			"id":          service.ID,
+			"title":       service.Title,
+			"description": service.Description,
+			"price":       service.Price,
+			"category":    service.Category,
+			"status":      service.Status,
+			"hostel_id":   service.HostelID,
+			"created_at":  service.CreatedAt,
+			"updated_at":  service.UpdatedAt,
+		})
+	}
+
+	exportedServiceRequests := []gin.H{}
+	for _, request := range serviceRequests {
+		role := "requester"
+		if request.RequesterID != userID {
+			role = "provider"
+		}
+		exportedServiceRequests = append(exportedServiceRequests, gin.H{
+			"id":           request.ID,
+			"role":         role,
+			"title":        request.Title,
+			"description":  request.Description,
+			"budget":       request.Budget,
+			"category":     request.Category,
+			"status":       request.Status,
+			"accepted_at":  request.AcceptedAt,
+			"completed_at": request.CompletedAt,
+			"created_at":   request.CreatedAt,
+			"updated_at":   request.UpdatedAt,
+		})
+	}
+
+	exportedFavorites := []gin.H{}
+	for _, favorite := range favorites {
+		exportedFavorites = append(exportedFavorites, gin.H{
+			"item_id":    favorite.ItemID,
+			"title":      favorite.Item.Title,
+			"created_at": favorite.CreatedAt,
+		})
+	}
+
+	exportedAppeals := []gin.H{}
+	for _, appeal := range appeals {
+		exportedAppeals = append(exportedAppeals, gin.H{
+			"target_type":   appeal.TargetType,
+			"target_id":     appeal.TargetID,
+			"message":       appeal.Message,
+			"status":        appeal.Status,
+			"decision_note": appeal.DecisionNote,
+			"decided_at":    appeal.DecidedAt,
+			"created_at":    appeal.CreatedAt,
+		})
+	}
+
+	exportedOffers := []gin.H{}
+	for _, offer := range offers {
+		exportedOffers = append(exportedOffers, gin.H{
+			"requested_item_id": offer.RequestedItemID,
+			"request_title":     offer.RequestedItem.Title,
+			"price":             offer.Price,
+			"quantity":          offer.Quantity,
+			"message":           offer.Message,
+			"image":             offer.Image,
+			"status":            offer.Status,
+			"created_at":        offer.CreatedAt,
+		})
+	}
+
+	exportedProposals := []gin.H{}
+	for _, proposal := range proposals {
+		exportedProposals = append(exportedProposals, gin.H{
+			"service_request_id":   proposal.ServiceRequestID,
+			"request_title":        proposal.ServiceRequest.Title,
+			"price":                proposal.Price,
+			"estimated_completion": proposal.EstimatedCompletion,
+			"message":              proposal.Message,
+			"status":               proposal.Status,
+			"created_at":           proposal.CreatedAt,
+		})
+	}
+
+	exportedBookings := []gin.H{}
+	for _, booking := range bookings {
+		exportedBookings = append(exportedBookings, gin.H{
+			"service_id":    booking.ServiceID,
+			"service_title": booking.Service.Title,
+			"requester_id":  booking.RequesterID,
+			"provider_id":   booking.ProviderID,
+			"starts_at":     booking.StartsAt,
+			"ends_at":       booking.EndsAt,
+			"note":          booking.Note,
+			"status":        booking.Status,
+			"created_at":    booking.CreatedAt,
+		})
+	}
+
+	return gin.H{
+		"exported_at": time.Now(),
+		"profile": gin.H{
+			"id":              user.ID,
+			"name":            user.Name,
+			"email":           user.Email,
+			"contact_details": user.ContactDetails,
+			"role":            user.Role,
+			"hostel":          user.Hostel.Name,
+			"created_at":      user.CreatedAt,
+			"updated_at":      user.UpdatedAt,
+		},
+		"items":                exportedItems,
+		"transaction_requests": exportedRequests,
+		"requested_items":      exportedRequestedItems,
+		"services":             exportedServices,
+		"service_requests":     exportedServiceRequests,
+		"favorites":            exportedFavorites,
+		"appeals":              exportedAppeals,
+		"offers":               exportedOffers,
+		"proposals":            exportedProposals,
+		"bookings":             exportedBookings,
+	}, nil
+}
+
+// DeleteAccount anonymizes the authenticated user's account and withdraws their open activity.
+// Rows referenced by other users (transaction requests, service requests) are kept so their
+// history stays intact; only the personal data on the user row is scrubbed.
+func DeleteAccount(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var req DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Password is incorrect"})
+		return
+	}
+
+	// The old password hash must not keep working, so replace it with a random one
+	randomPassword, err := generateToken(32)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete account"})
+		return
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(randomPassword), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete account"})
+		return
+	}
+
+	var closedRequestedItems, cancelledServiceRequests []uint
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Favorites are private to the user, and favorites on their items point at listings that are going away
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.Favorite{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("item_id IN (?)", tx.Model(&models.Item{}).Select("id").Where("user_id = ?", user.ID)).
+			Delete(&models.Favorite{}).Error; err != nil {
+			return err
+		}
+
+		// Withdraw anything still live; completed records stay for the other party
+		if err := tx.Model(&models.Item{}).Where("user_id = ? AND status IN ?", user.ID, []string{"pending", "needs_review", "approved"}).
+			Update("status", "removed").Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Service{}).Where("user_id = ? AND status IN ?", user.ID, []string{"pending", "needs_review", "approved", "paused"}).
+			Update("status", "removed").Error; err != nil {
+			return err
+		}
+		// Release reservations made by accepting offers first, so requested items they reopen are closed below
+		var pendingRequests []models.TransactionRequest
+		if err := tx.Where("(buyer_id = ? OR seller_id = ?) AND status = ?", user.ID, user.ID, "pending").
+			Find(&pendingRequests).Error; err != nil {
+			return err
+		}
+		for _, request := range pendingRequests {
+			if err := releaseOfferReservation(tx, request); err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&models.TransactionRequest{}).Where("(buyer_id = ? OR seller_id = ?) AND status = ?", user.ID, user.ID, "pending").
+			Update("status", "cancelled").Error; err != nil {
+			return err
+		}
+		// Offers and proposals on the requests closed here are declined once the deletion is committed
+		if err := tx.Model(&models.RequestedItem{}).Where("buyer_id = ? AND status = ?", user.ID, "open").
+			Pluck("id", &closedRequestedItems).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.RequestedItem{}).Where("id IN ?", closedRequestedItems).
+			Update("status", "closed").Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.ServiceRequest{}).Where("requester_id = ? AND status = ?", user.ID, "open").
+			Pluck("id", &cancelledServiceRequests).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.ServiceRequest{}).Where("id IN ?", cancelledServiceRequests).
+			Update("status", "cancelled").Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Offer{}).Where("seller_id = ? AND status IN ?", user.ID, activeOfferStatuses).
+			Update("status", "withdrawn").Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Proposal{}).Where("provider_id = ? AND status = ?", user.ID, "open").
+			Update("status", "withdrawn").Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.ServiceSlot{}).Where("provider_id = ? AND status IN ?", user.ID, []string{"open", "booked"}).
+			Update("status", "removed").Error; err != nil {
+			return err
+		}
+		// Slots the user booked go back to their providers before the bookings are cancelled
+		if err := tx.Model(&models.ServiceSlot{}).Where("status = ? AND starts_at > ? AND id IN (?)", "booked", time.Now(),
+			tx.Model(&models.Booking{}).Select("slot_id").Where("requester_id = ? AND status IN ?", user.ID, activeBookingStatuses)).
+			Update("status", "open").Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Booking{}).Where("(requester_id = ? OR provider_id = ?) AND status IN ?", user.ID, user.ID, activeBookingStatuses).
+			Updates(map[string]interface{}{"status": "cancelled", "cancel_reason": "The account was deleted.", "cancelled_at": time.Now()}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.PasswordReset{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.EmailChange{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.CalendarFeed{}).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		return tx.Model(&user).Updates(map[string]interface{}{
+			"name":            "Deleted User",
+			"email":           fmt.Sprintf("deleted-%d@deleted.openex.invalid", user.ID),
+			"password":        string(hashedPassword),
+			"contact_details": "",
+			"role":            "user",
+			"anonymized_at":   &now,
+		}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete account"})
+		return
+	}
+
+	for _, id := range closedRequestedItems {
+		declineActiveOffers(id, "The buyer deleted their account.")
+	}
+	for _, id := range cancelledServiceRequests {
+		declineOpenProposals(id, "The requester deleted their account.")
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Your account has been deleted"})
+}
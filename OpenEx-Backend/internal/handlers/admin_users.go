@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"OpenEx-Backend/internal/database"
+	"OpenEx-Backend/internal/models"
+	"OpenEx-Backend/internal/rbac"
+	"OpenEx-Backend/internal/services/audit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SuspendUserRequest is the request payload for suspending a user
+type SuspendUserRequest struct {
+	Hours  int    `json:"hours" binding:"required,min=1"`
+	Reason string `json:"reason" binding:"required"`
+}
+
+// BanUserRequest is the request payload for banning a user
+type BanUserRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// accountStatus describes whether a user is active, suspended, banned or deleted
+func accountStatus(user models.User) string {
+	if user.AnonymizedAt != nil {
+		return "deleted"
+	} else if user.IsBanned() {
+		return "banned"
+	} else if user.IsSuspended() {
+		return "suspended"
+	}
+	return "active"
+}
+
+// adminUserSummary is the view of a user shown to staff
+func adminUserSummary(user models.User) gin.H {
+	return gin.H{
+		"id":                user.ID,
+		"name":              user.Name,
+		"email":             user.Email,
+		"contactDetails":    user.ContactDetails,
+		"role":              user.Role,
+		"hostelId":          user.HostelID,
+		"hostel":            user.Hostel.Name,
+		"moderatedHostelId": user.ModeratedHostelID,
+		"status":            accountStatus(user),
+		"suspendedUntil":    user.SuspendedUntil,
+		"bannedAt":          user.BannedAt,
+		"restrictionReason": user.RestrictionReason,
+		"hiddenAt":          user.HiddenAt,
+		"createdAt":         user.CreatedAt,
+	}
+}
+
+// SearchUsers lists users matching a name/email query, role and status (staff only)
+func SearchUsers(c *gin.Context) {
+	query := database.DB.Preload("Hostel").Where("anonymized_at IS NULL")
+
+	if q := c.Query("q"); q != "" {
+		like := "%" + q + "%"
+		query = query.Where("name LIKE ? OR email LIKE ?", like, like)
+	}
+	if role := c.Query("role"); role != "" {
+		query = query.Where("role = ?", role)
+	}
+	if hostelID := c.Query("hostel_id"); hostelID != "" {
+		query = query.Where("hostel_id = ?", hostelID)
+	}
+
+	switch c.Query("status") {
+	case "banned":
+		query = query.Where("banned_at IS NOT NULL")
+	case "suspended":
+		query = query.Where("banned_at IS NULL AND suspended_until > ?", time.Now())
+	case "active":
+		query = query.Where("banned_at IS NULL AND (suspended_until IS NULL OR suspended_until <= ?)", time.Now())
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	var users []models.User
+	if err := query.Order("id").Limit(limit).Offset(offset).Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search users"})
+		return
+	}
+
+	results := []gin.H{}
+	for _, user := range users {
+		results = append(results, adminUserSummary(user))
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// GetUserForAdmin returns a user's profile together with their listings and transaction history (staff only)
+func GetUserForAdmin(c *gin.Context) {
+	var user models.User
+	if err := database.DB.Preload("Hostel").First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var items []models.Item
+	database.DB.Where("user_id = ?", user.ID).Order("created_at DESC").Find(&items)
+
+	var services []models.Service
+	database.DB.Where("user_id = ?", user.ID).Order("created_at DESC").Find(&services)
+
+	var requestedItems []models.RequestedItem
+	database.DB.Where("buyer_id = ?", user.ID).Order("created_at DESC").Find(&requestedItems)
+
+	var serviceRequests []models.ServiceRequest
+	database.DB.Where("requester_id = ? OR provider_id = ?", user.ID, user.ID).Order("created_at DESC").Find(&serviceRequests)
+
+	var transactions []models.TransactionRequest
+	database.DB.Preload("Item").
+		Where("buyer_id = ? OR seller_id = ?", user.ID, user.ID).
+		Order("created_at DESC").
+		Find(&transactions)
+
+	transactionHistory := []gin.H{}
+	for _, transaction := range transactions {
+		transactionHistory = append(transactionHistory, gin.H{
+			"id":        transaction.ID,
+			"buyerId":   transaction.BuyerID,
+			"sellerId":  transaction.SellerID,
+			"itemId":    transaction.ItemID,
+			"itemTitle": transaction.Item.Title,
+			"type":      transaction.Type,
+			"status":    transaction.Status,
+			"quantity":  transaction.Quantity,
+			"createdAt": transaction.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user":             adminUserSummary(user),
+		"items":            items,
+		"services":         services,
+		"requested_items":  requestedItems,
+		"service_requests": serviceRequests,
+		"transactions":     transactionHistory,
+	})
+}
+
+// SuspendUser locks a user out for a number of hours and hides their listings (admin only)
+func SuspendUser(c *gin.Context) {
+	var req SuspendUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := loadRestrictableUser(c)
+	if !ok {
+		return
+	}
+
+	previousStatus := accountStatus(user)
+	until := time.Now().Add(time.Duration(req.Hours) * time.Hour)
+	user.SuspendedUntil = &until
+	user.RestrictionReason = req.Reason
+
+	if err := database.DB.Save(&user).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to suspend user"})
+		return
+	}
+
+	audit.Record(audit.UserActor(c.MustGet("user").(models.User)), audit.Entry{
+		Action:     "user.suspend",
+		TargetType: "user",
+		TargetID:   user.ID,
+		FromStatus: previousStatus,
+		ToStatus:   accountStatus(user),
+		Reason:     req.Reason,
+	})
+
+	c.JSON(http.StatusOK, adminUserSummary(user))
+}
+
+// BanUser permanently locks a user out and hides their listings (admin only)
+func BanUser(c *gin.Context) {
+	var req BanUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := loadRestrictableUser(c)
+	if !ok {
+		return
+	}
+
+	previousStatus := accountStatus(user)
+	now := time.Now()
+	user.BannedAt = &now
+	user.RestrictionReason = req.Reason
+
+	if err := database.DB.Save(&user).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to ban user"})
+		return
+	}
+
+	audit.Record(audit.UserActor(c.MustGet("user").(models.User)), audit.Entry{
+		Action:     "user.ban",
+		TargetType: "user",
+		TargetID:   user.ID,
+		FromStatus: previousStatus,
+		ToStatus:   accountStatus(user),
+		Reason:     req.Reason,
+	})
+
+	c.JSON(http.StatusOK, adminUserSummary(user))
+}
+
+// ReinstateUser lifts a suspension or ban, and unhides listings hidden after reports (admin only)
+func ReinstateUser(c *gin.Context) {
+	user, ok := loadRestrictableUser(c)
+	if !ok {
+		return
+	}
+
+	previousStatus := accountStatus(user)
+	user.SuspendedUntil = nil
+	user.BannedAt = nil
+	user.HiddenAt = nil
+	user.RestrictionReason = ""
+
+	if err := database.DB.Save(&user).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reinstate user"})
+		return
+	}
+
+	audit.Record(audit.UserActor(c.MustGet("user").(models.User)), audit.Entry{
+		Action:     "user.reinstate",
+		TargetType: "user",
+		TargetID:   user.ID,
+		FromStatus: previousStatus,
+		ToStatus:   accountStatus(user),
+		Reason:     "",
+	})
+
+	c.JSON(http.StatusOK, adminUserSummary(user))
+}
+
+// Helper function to load the target user of a suspend/ban/reinstate action, writing the error response if it can't be acted on
+func loadRestrictableUser(c *gin.Context) (models.User, bool) {
+	admin := c.MustGet("user").(models.User)
+
+	var user models.User
+	if err := database.DB.Preload("Hostel").First(&user, c.Param("id")).Error; err != nil || user.AnonymizedAt != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return user, false
+	}
+
+	if user.ID == admin.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot restrict your own account"})
+		return user, false
+	}
+
+	if rbac.IsStaff(user.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Remove the user's staff role before restricting them"})
+		return user, false
+	}
+
+	return user, true
+}
@@ -0,0 +1,350 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"OpenEx-Backend/internal/database"
+	"OpenEx-Backend/internal/models"
+	"OpenEx-Backend/internal/rbac"
+	"OpenEx-Backend/internal/services/audit"
+	"OpenEx-Backend/internal/services/matcher"
+	"OpenEx-Backend/internal/services/moderationlog"
+	"OpenEx-Backend/internal/services/notify"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AppealRequest is the request payload for appealing a rejection
+type AppealRequest struct {
+	Message string `json:"message" binding:"required"`
+}
+
+// AppealDecisionRequest is the request payload for granting or denying an appeal
+type AppealDecisionRequest struct {
+	Note string `json:"note"`
+}
+
+// appealListing is what the appeal handlers need to know about a rejected listing
+type appealListing struct {
+	OwnerID         uint
+	HostelID        uint
+	Title           string
+	Status          string
+	RejectionReason string
+	SubmittedAt     time.Time // Start of the current moderation round
+}
+
+// loadAppealListing loads the item or service an appeal is about
+func loadAppealListing(targetType string, id uint) (appealListing, error) {
+	if targetType == "service" {
+		var service models.Service
+		if err := database.DB.First(&service, id).Error; err != nil {
+			return appealListing{}, err
+		}
+		submittedAt := service.CreatedAt
+		if service.SubmittedAt != nil {
+			submittedAt = *service.SubmittedAt
+		}
+		return appealListing{service.UserID, service.HostelID, service.Title, service.Status, service.RejectionReason, submittedAt}, nil
+	}
+
+	var item models.Item
+	if err := database.DB.First(&item, id).Error; err != nil {
+		return appealListing{}, err
+	}
+	submittedAt := item.CreatedAt
+	if item.SubmittedAt != nil {
+		submittedAt = *item.SubmittedAt
+	}
+	return appealListing{item.UserID, item.HostelID, item.Title, item.Status, item.RejectionReason, submittedAt}, nil
+}
+
+// AppealItem lets the owner of a rejected item ask staff to reconsider
+func AppealItem(c *gin.Context) {
+	fileAppeal(c, "item")
+}
+
+// AppealService lets the owner of a rejected service ask staff to reconsider
+func AppealService(c *gin.Context) {
+	fileAppeal(c, "service")
+}
+
+// fileAppeal stores an appeal against the current rejection of a listing. Each rejection
+// can be appealed once; resubmitting the listing starts a new moderation round.
+func fileAppeal(c *gin.Context, targetType string) {
+	user := c.MustGet("user").(models.User)
+
+	var req AppealRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	message := strings.TrimSpace(req.Message)
+	if message == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "message can't be empty"})
+		return
+	}
+
+	targetID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	listing, err := loadAppealListing(targetType, uint(targetID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Listing not found"})
+		return
+	}
+
+	if listing.OwnerID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the owner can appeal this " + targetType})
+		return
+	}
+
+	if listing.Status != "rejected" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only rejected listings can be appealed"})
+		return
+	}
+
+	appeal := models.Appeal{
+		TargetType:      targetType,
+		TargetID:        uint(targetID),
+		SubmittedAt:     &listing.SubmittedAt,
+		UserID:          user.ID,
+		HostelID:        listing.HostelID,
+		Message:         message,
+		RejectionReason: listing.RejectionReason,
+		Status:          "pending",
+	}
+
+	// The unique index on the moderation round turns a second appeal, even a concurrent one,
+	// into a duplicate key
+	err = database.DB.Create(&appeal).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		var previous models.Appeal
+		database.DB.Where("target_type = ? AND target_id = ? AND submitted_at = ?", targetType, targetID, listing.SubmittedAt).
+			First(&previous)
+		c.JSON(http.StatusConflict, gin.H{"error": "This rejection has already been appealed", "status": previous.Status})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit appeal"})
+		return
+	}
+
+	audit.Record(audit.UserActor(user), audit.Entry{
+		Action:     targetType + ".appeal",
+		TargetType: targetType,
+		TargetID:   appeal.TargetID,
+		FromStatus: listing.Status,
+		ToStatus:   listing.Status,
+		Reason:     appeal.Message,
+	})
+
+	c.JSON(http.StatusCreated, appeal)
+}
+
+// GetMyAppeals returns the appeals filed by the authenticated user
+func GetMyAppeals(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var appeals []models.Appeal
+	database.DB.Where("user_id = ?", user.ID).Order("created_at DESC").Find(&appeals)
+	c.JSON(http.StatusOK, appeals)
+}
+
+// appealSummary is the view of an appeal shown to staff
+func appealSummary(appeal models.Appeal) gin.H {
+	return gin.H{
+		"id":              appeal.ID,
+		"targetType":      appeal.TargetType,
+		"targetId":        appeal.TargetID,
+		"userId":          appeal.UserID,
+		"owner":           appeal.User.Name,
+		"hostelId":        appeal.HostelID,
+		"message":         appeal.Message,
+		"rejectionReason": appeal.RejectionReason,
+		"status":          appeal.Status,
+		"decidedById":     appeal.DecidedByID,
+		"decisionNote":    appeal.DecisionNote,
+		"decidedAt":       appeal.DecidedAt,
+		"createdAt":       appeal.CreatedAt,
+	}
+}
+
+// ListAppeals returns appeals, oldest first, defaulting to the pending ones
+// (staff only, limited to the moderator's hostel)
+func ListAppeals(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	query := database.DB.Preload("User")
+	if hostelID := rbac.HostelScope(user); hostelID != nil {
+		query = query.Where("hostel_id = ?", *hostelID)
+	}
+	if status := c.DefaultQuery("status", "pending"); status != "all" {
+		query = query.Where("status = ?", status)
+	}
+	if targetType := c.Query("target_type"); targetType != "" {
+		query = query.Where("target_type = ?", targetType)
+	}
+
+	var appeals []models.Appeal
+	if err := query.Order("created_at, id").Find(&appeals).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch appeals"})
+		return
+	}
+
+	results := []gin.H{}
+	for _, appeal := range appeals {
+		results = append(results, appealSummary(appeal))
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// GrantAppeal overturns the rejection and publishes the listing (moderators only)
+func GrantAppeal(c *gin.Context) {
+	decideAppeal(c, true)
+}
+
+// DenyAppeal upholds the rejection; the owner can still edit and resubmit (moderators only)
+func DenyAppeal(c *gin.Context) {
+	decideAppeal(c, false)
+}
+
+// errAppealDecided is returned when another moderator decided the appeal first
+var errAppealDecided = errors.New("appeal has already been decided")
+
+// errAppealListingChanged is returned when the appealed listing left "rejected" meanwhile
+var errAppealListingChanged = errors.New("listing is no longer rejected")
+
+// decideAppeal records a decision on a pending appeal and notifies the owner
+func decideAppeal(c *gin.Context, granted bool) {
+	user := c.MustGet("user").(models.User)
+
+	// The note is optional, so an empty body is fine
+	var req AppealDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var appeal models.Appeal
+	if err := database.DB.Preload("User").First(&appeal, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Appeal not found"})
+		return
+	}
+
+	if !rbac.CanAccessHostel(user, appeal.HostelID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only decide appeals in your hostel"})
+		return
+	}
+
+	if appeal.Status != "pending" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Appeal has already been decided"})
+		return
+	}
+
+	listing, err := loadAppealListing(appeal.TargetType, appeal.TargetID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Listing not found"})
+		return
+	}
+
+	// A resubmitted listing is back in moderation, so granting would skip the new review
+	if granted && listing.Status != "rejected" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The listing is no longer rejected"})
+		return
+	}
+
+	now := time.Now()
+	action := "deny"
+	decision := map[string]interface{}{
+		"status":        "denied",
+		"decided_by_id": &user.ID,
+		"decision_note": strings.TrimSpace(req.Note),
+		"decided_at":    &now,
+	}
+	if granted {
+		action = "grant"
+		decision["status"] = "granted"
+	}
+
+	newStatus := listing.Status
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Of two moderators deciding at once, only the first gets through
+		result := tx.Model(&appeal).Omit(clause.Associations).Where("status = ?", "pending").Updates(decision)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errAppealDecided
+		}
+		if !granted {
+			return nil
+		}
+
+		// The owner may have resubmitted since the check above; the new version needs its own review
+		var model interface{} = &models.Item{}
+		if appeal.TargetType == "service" {
+			model = &models.Service{}
+		}
+		result = tx.Model(model).Where("id = ? AND status = ?", appeal.TargetID, "rejected").Updates(map[string]interface{}{
+			"status":           "approved",
+			"rejection_reason": "",
+			"review_reason":    "",
+			"review_priority":  0,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errAppealListingChanged
+		}
+		newStatus = "approved"
+		return nil
+	})
+	if errors.Is(err, errAppealDecided) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Appeal has already been decided"})
+		return
+	}
+	if errors.Is(err, errAppealListingChanged) {
+		c.JSON(http.StatusConflict, gin.H{"error": "The listing is no longer rejected"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decide appeal"})
+		return
+	}
+
+	audit.Record(audit.UserActor(user), audit.Entry{
+		Action:     appeal.TargetType + ".appeal_" + action,
+		TargetType: appeal.TargetType,
+		TargetID:   appeal.TargetID,
+		FromStatus: listing.Status,
+		ToStatus:   newStatus,
+		Reason:     appeal.DecisionNote,
+	})
+
+	// A granted appeal overturns the earlier rejection, which shows up in the moderation stats
+	if granted {
+		moderationlog.RecordHuman(appeal.TargetType, appeal.TargetID, user, "approved",
+			strings.TrimSpace("Appeal granted. "+appeal.DecisionNote))
+
+		var item models.Item
+		if appeal.TargetType == "item" && database.DB.First(&item, appeal.TargetID).Error == nil {
+			go matcher.ItemApproved(item)
+		}
+	}
+
+	go notify.AppealDecided(appeal.User, appeal.TargetType, listing.Title, granted, appeal.DecisionNote)
+
+	c.JSON(http.StatusOK, appealSummary(appeal))
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"OpenEx-Backend/internal/database"
+	"OpenEx-Backend/internal/middleware"
 	"OpenEx-Backend/internal/models"
 
 	"github.com/gin-gonic/gin"
@@ -123,6 +124,11 @@ func Login(c *gin.Context) {
 		return
 	}
 
+	if restricted, details := middleware.AccountRestriction(user); restricted {
+		c.JSON(http.StatusForbidden, details)
+		return
+	}
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"user_id": user.ID,
 		"exp":     time.Now().Add(time.Hour * 24).Unix(),
@@ -184,6 +190,11 @@ func GoogleAuth(c *gin.Context) {
 		}
 	}
 
+	if restricted, details := middleware.AccountRestriction(user); restricted {
+		c.JSON(http.StatusForbidden, details)
+		return
+	}
+
 	// Generate a JWT token
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"user_id": user.ID,
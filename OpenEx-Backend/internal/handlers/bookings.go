@@ -0,0 +1,477 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"OpenEx-Backend/internal/database"
+	"OpenEx-Backend/internal/models"
+	"OpenEx-Backend/internal/services/audit"
+	"OpenEx-Backend/internal/services/notify"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxSlotLength is the longest a single availability slot may be
+const maxSlotLength = 12 * time.Hour
+
+// SlotRequest is the request payload for publishing an availability slot
+type SlotRequest struct {
+	StartsAt time.Time `json:"starts_at" binding:"required"`
+	EndsAt   time.Time `json:"ends_at" binding:"required"`
+}
+
+// BookingRequest is the request payload for booking a slot
+type BookingRequest struct {
+	Note string `json:"note"`
+}
+
+// CancelBookingRequest is the optional request payload for cancelling a booking
+type CancelBookingRequest struct {
+	Reason string `json:"reason"`
+}
+
+// activeBookingStatuses are the statuses of bookings that still hold their slot
+var activeBookingStatuses = []string{"requested", "confirmed"}
+
+// errSlotTaken is returned when a slot was booked by someone else first
+var errSlotTaken = errors.New("slot is already booked")
+
+// errBookingClosed is returned when a booking was completed or cancelled meanwhile
+var errBookingClosed = errors.New("booking is no longer active")
+
+// errScheduleConflict is returned when a new slot or booking overlaps one its user already has
+var errScheduleConflict = errors.New("overlaps an existing slot or booking")
+
+// lockSchedule locks the user's row until the transaction ends, so that checking a user's
+// slots or bookings for overlaps and adding a new one can't interleave with another request
+func lockSchedule(tx *gorm.DB, userID uint) error {
+	var locked models.User
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&locked, userID).Error
+}
+
+// CreateSlot publishes a time the provider is available for one of their approved services
+func CreateSlot(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var req SlotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !req.StartsAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "starts_at must be in the future"})
+		return
+	}
+	if !req.EndsAt.After(req.StartsAt) || req.EndsAt.Sub(req.StartsAt) > maxSlotLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ends_at must be after starts_at and at most 12 hours later"})
+		return
+	}
+
+	var service models.Service
+	if err := database.DB.First(&service, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
+		return
+	}
+
+	if service.UserID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the provider can publish slots for this service"})
+		return
+	}
+
+	if service.Status != "approved" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Slots can only be published for approved services", "status": service.Status})
+		return
+	}
+
+	slot := models.ServiceSlot{
+		ServiceID:  service.ID,
+		ProviderID: user.ID,
+		StartsAt:   req.StartsAt,
+		EndsAt:     req.EndsAt,
+		Status:     "open",
+	}
+
+	var conflict models.ServiceSlot
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockSchedule(tx, user.ID); err != nil {
+			return err
+		}
+
+		// A provider can only be in one place at a time, whichever service the slot is for
+		err := tx.Where("provider_id = ? AND status IN ? AND starts_at < ? AND ends_at > ?", user.ID, []string{"open", "booked"}, req.EndsAt, req.StartsAt).
+			First(&conflict).Error
+		if err == nil {
+			return errScheduleConflict
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		return tx.Create(&slot).Error
+	})
+	if errors.Is(err, errScheduleConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": "This slot overlaps another of your slots", "conflictingSlot": conflict})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create slot"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, slot)
+}
+
+// ListSlots returns the upcoming slots of an approved service, soonest first. Booked slots are
+// included so the calendar shows them as taken.
+func ListSlots(c *gin.Context) {
+	var service models.Service
+	if err := database.DB.Where("status = ?", "approved").Scopes(visibleOwner("user_id")).
+		First(&service, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
+		return
+	}
+
+	var slots []models.ServiceSlot
+	database.DB.Where("service_id = ? AND status IN ? AND starts_at > ?", service.ID, []string{"open", "booked"}, time.Now()).
+		Order("starts_at").
+		Find(&slots)
+
+	c.JSON(http.StatusOK, slots)
+}
+
+// RemoveSlot withdraws an open slot (provider only). Booked slots have to be cancelled through
+// their booking so the requester is told.
+func RemoveSlot(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var slot models.ServiceSlot
+	if err := database.DB.First(&slot, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Slot not found"})
+		return
+	}
+
+	if slot.ProviderID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the provider can remove this slot"})
+		return
+	}
+
+	result := database.DB.Model(&models.ServiceSlot{}).
+		Where("id = ? AND status = ?", slot.ID, "open").
+		Update("status", "removed")
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove slot"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only open slots can be removed; cancel the booking first", "status": slot.Status})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Slot removed successfully"})
+}
+
+// BookSlot reserves an open slot for the authenticated user. The booking starts out requested
+// until the provider confirms it.
+func BookSlot(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var req BookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var slot models.ServiceSlot
+	if err := database.DB.Preload("Service.User").First(&slot, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Slot not found"})
+		return
+	}
+
+	if slot.ProviderID == user.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot book your own slot"})
+		return
+	}
+
+	if slot.Status != "open" || !slot.StartsAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This slot is no longer available"})
+		return
+	}
+
+	provider := slot.Service.User
+	if slot.Service.Status != "approved" || provider.AnonymizedAt != nil || provider.IsBanned() || provider.IsSuspended() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This service is currently unavailable"})
+		return
+	}
+
+	booking := models.Booking{
+		SlotID:      slot.ID,
+		ServiceID:   slot.ServiceID,
+		RequesterID: user.ID,
+		ProviderID:  slot.ProviderID,
+		StartsAt:    slot.StartsAt,
+		EndsAt:      slot.EndsAt,
+		Note:        strings.TrimSpace(req.Note),
+		Status:      "requested",
+	}
+
+	var conflict models.Booking
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockSchedule(tx, user.ID); err != nil {
+			return err
+		}
+
+		// Don't let the requester be booked into two sessions at once
+		err := tx.Where("requester_id = ? AND status IN ? AND starts_at < ? AND ends_at > ?", user.ID, activeBookingStatuses, slot.EndsAt, slot.StartsAt).
+			First(&conflict).Error
+		if err == nil {
+			return errScheduleConflict
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		// The status condition lets only one of two simultaneous bookings through
+		result := tx.Model(&models.ServiceSlot{}).Where("id = ? AND status = ?", slot.ID, "open").
+			Update("status", "booked")
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errSlotTaken
+		}
+		return tx.Create(&booking).Error
+	})
+	if errors.Is(err, errScheduleConflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": "You already have a booking at this time", "conflictingBookingId": conflict.ID})
+		return
+	}
+	if errors.Is(err, errSlotTaken) {
+		c.JSON(http.StatusConflict, gin.H{"error": "This slot was just booked by someone else"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to book slot"})
+		return
+	}
+
+	go notify.BookingRequested(provider, user.Name, slot.Service.Title, slot.StartsAt)
+
+	c.JSON(http.StatusCreated, booking)
+}
+
+// bookingSummary is the view of a booking shown to one of its parties. Contact details of the
+// other party are only included once the provider has confirmed.
+func bookingSummary(booking models.Booking, viewer models.User) gin.H {
+	role, other := "requester", booking.Provider
+	if booking.ProviderID == viewer.ID {
+		role, other = "provider", booking.Requester
+	}
+
+	summary := gin.H{
+		"id":           booking.ID,
+		"role":         role,
+		"slotId":       booking.SlotID,
+		"serviceId":    booking.ServiceID,
+		"serviceTitle": booking.Service.Title,
+		"requesterId":  booking.RequesterID,
+		"requester":    booking.Requester.Name,
+		"providerId":   booking.ProviderID,
+		"provider":     booking.Provider.Name,
+		"startsAt":     booking.StartsAt,
+		"endsAt":       booking.EndsAt,
+		"note":         booking.Note,
+		"status":       booking.Status,
+		"cancelReason": booking.CancelReason,
+		"createdAt":    booking.CreatedAt,
+	}
+	if booking.Status == "confirmed" || booking.Status == "completed" {
+		summary["contact"] = other.ContactDetails
+	}
+	return summary
+}
+
+// GetMyBookings returns the bookings the authenticated user made or received, soonest first.
+// ?role=requester or ?role=provider narrows the list to one side.
+func GetMyBookings(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	query := database.DB.Preload("Service").Preload("Requester").Preload("Provider").Order("starts_at")
+	switch c.Query("role") {
+	case "":
+		query = query.Where("requester_id = ? OR provider_id = ?", user.ID, user.ID)
+	case "requester":
+		query = query.Where("requester_id = ?", user.ID)
+	case "provider":
+		query = query.Where("provider_id = ?", user.ID)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "role must be requester or provider"})
+		return
+	}
+
+	var bookings []models.Booking
+	query.Find(&bookings)
+
+	results := []gin.H{}
+	for _, booking := range bookings {
+		results = append(results, bookingSummary(booking, user))
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// loadBooking loads a booking with its service and parties
+func loadBooking(c *gin.Context) (models.Booking, bool) {
+	var booking models.Booking
+	if err := database.DB.Preload("Service").Preload("Requester").Preload("Provider").
+		First(&booking, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		return booking, false
+	}
+	return booking, true
+}
+
+// ConfirmBooking accepts a requested booking (provider only)
+func ConfirmBooking(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	booking, ok := loadBooking(c)
+	if !ok {
+		return
+	}
+
+	if booking.ProviderID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the provider can confirm this booking"})
+		return
+	}
+
+	now := time.Now()
+	result := database.DB.Model(&models.Booking{}).
+		Where("id = ? AND status = ? AND starts_at > ?", booking.ID, "requested", now).
+		Updates(map[string]interface{}{"status": "confirmed", "confirmed_at": now})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to confirm booking"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only upcoming requested bookings can be confirmed", "status": booking.Status})
+		return
+	}
+
+	booking.Status = "confirmed"
+	booking.ConfirmedAt = &now
+
+	go notify.BookingConfirmed(booking.Requester, booking.Service.Title, booking.StartsAt)
+
+	c.JSON(http.StatusOK, bookingSummary(booking, user))
+}
+
+// CancelBooking cancels a requested or confirmed booking and frees its slot (either party)
+func CancelBooking(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var req CancelBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	booking, ok := loadBooking(c)
+	if !ok {
+		return
+	}
+
+	if booking.RequesterID != user.ID && booking.ProviderID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the requester or the provider can cancel this booking"})
+		return
+	}
+
+	now := time.Now()
+	reason := strings.TrimSpace(req.Reason)
+	previousStatus := booking.Status
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Booking{}).
+			Where("id = ? AND status IN ?", booking.ID, activeBookingStatuses).
+			Updates(map[string]interface{}{
+				"status":        "cancelled",
+				"cancel_reason": reason,
+				"cancelled_by":  user.ID,
+				"cancelled_at":  now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errBookingClosed
+		}
+
+		// Free the slot for someone else if it hasn't started yet
+		return tx.Model(&models.ServiceSlot{}).
+			Where("id = ? AND status = ? AND starts_at > ?", booking.SlotID, "booked", now).
+			Update("status", "open").Error
+	})
+	if errors.Is(err, errBookingClosed) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only requested or confirmed bookings can be cancelled", "status": booking.Status})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel booking"})
+		return
+	}
+
+	booking.Status = "cancelled"
+	booking.CancelReason = reason
+
+	audit.Record(audit.UserActor(user), audit.Entry{
+		Action:     "booking.cancel",
+		TargetType: "booking",
+		TargetID:   booking.ID,
+		FromStatus: previousStatus,
+		ToStatus:   booking.Status,
+		Reason:     reason,
+	})
+
+	other := booking.Provider
+	if booking.ProviderID == user.ID {
+		other = booking.Requester
+	}
+	go notify.BookingCancelled(other, booking.Service.Title, booking.StartsAt, reason)
+
+	c.JSON(http.StatusOK, bookingSummary(booking, user))
+}
+
+// CompleteBooking marks a confirmed session that has started as done (requester only)
+func CompleteBooking(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	booking, ok := loadBooking(c)
+	if !ok {
+		return
+	}
+
+	if booking.RequesterID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the requester can mark a booking as completed"})
+		return
+	}
+
+	now := time.Now()
+	result := database.DB.Model(&models.Booking{}).
+		Where("id = ? AND status = ? AND starts_at <= ?", booking.ID, "confirmed", now).
+		Updates(map[string]interface{}{"status": "completed", "completed_at": now})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete booking"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only confirmed bookings that have started can be completed", "status": booking.Status})
+		return
+	}
+
+	booking.Status = "completed"
+	booking.CompletedAt = &now
+	c.JSON(http.StatusOK, bookingSummary(booking, user))
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"OpenEx-Backend/internal/config"
+	"OpenEx-Backend/internal/database"
+	"OpenEx-Backend/internal/models"
+	"OpenEx-Backend/internal/services/calendar"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetCalendarFeed returns the URL of the authenticated user's private calendar feed, creating
+// the feed on first use
+func GetCalendarFeed(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var feed models.CalendarFeed
+	err := database.DB.Where("user_id = ?", user.ID).First(&feed).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		feed, err = resetCalendarToken(user)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load calendar feed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, calendarFeedResponse(feed))
+}
+
+// ResetCalendarFeed replaces the feed token, so a leaked feed URL stops working
+func ResetCalendarFeed(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	feed, err := resetCalendarToken(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset calendar feed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, calendarFeedResponse(feed))
+}
+
+// resetCalendarToken gives the user's feed a new token, creating the feed if needed
+func resetCalendarToken(user models.User) (models.CalendarFeed, error) {
+	token, err := generateToken(32)
+	if err != nil {
+		return models.CalendarFeed{}, err
+	}
+
+	var feed models.CalendarFeed
+	err = database.DB.Where(models.CalendarFeed{UserID: user.ID}).
+		Assign(models.CalendarFeed{Token: token}).
+		FirstOrCreate(&feed).Error
+	return feed, err
+}
+
+// calendarFeedResponse describes the feed URLs. The webcal:// variant makes calendar apps
+// subscribe instead of importing once.
+func calendarFeedResponse(feed models.CalendarFeed) gin.H {
+	// The request's Host and X-Forwarded-Proto are up to the client, so links use the configured URL
+	url := strings.TrimSuffix(config.BackendURL(), "/") + "/calendar/feed/" + feed.Token + ".ics"
+	webcalURL := "webcal://" + strings.TrimPrefix(strings.TrimPrefix(url, "https://"), "http://")
+
+	return gin.H{
+		"url":           url,
+		"webcalUrl":     webcalURL,
+		"lastFetchedAt": feed.LastFetchedAt,
+	}
+}
+
+// ServeCalendarFeed returns the upcoming commitments of the feed's owner as an iCalendar
+// document. The token in the URL is the only authentication, since calendar apps can't log in.
+func ServeCalendarFeed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	var feed models.CalendarFeed
+	if token == "" || database.DB.Where("token = ?", token).First(&feed).Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendar feed not found"})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, feed.UserID).Error; err != nil || user.AnonymizedAt != nil || user.IsBanned() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendar feed not found"})
+		return
+	}
+
+	events, err := calendar.Upcoming(user)
+	if err != nil {
+		log.Printf("Error building calendar feed for user #%d: %v", user.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build calendar feed"})
+		return
+	}
+
+	database.DB.Model(&feed).Update("last_fetched_at", time.Now())
+
+	c.Header("Content-Disposition", `inline; filename="openex.ics"`)
+	c.Header("Cache-Control", "private, max-age=900")
+	writeCalendar(c, "OpenEx", events)
+}
+
+// DownloadCalendarEvent returns one of the authenticated user's upcoming events as an .ics
+// file, for adding it to a calendar without subscribing to the feed
+func DownloadCalendarEvent(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	id, err := strconv.ParseUint(strings.TrimSuffix(c.Param("id"), ".ics"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	eventType := c.Param("type")
+	event, err := calendar.Find(user, eventType, uint(id))
+	if errors.Is(err, calendar.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load event"})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="openex-%s-%d.ics"`, eventType, id))
+	writeCalendar(c, "OpenEx", []calendar.Event{event})
+}
+
+// writeCalendar sends events as an iCalendar document
+func writeCalendar(c *gin.Context, name string, events []calendar.Event) {
+	c.Header("Content-Type", "text/calendar; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := calendar.Write(c.Writer, name, events); err != nil {
+		c.Error(err)
+	}
+}
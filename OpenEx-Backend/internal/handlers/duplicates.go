@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"OpenEx-Backend/internal/database"
+	"OpenEx-Backend/internal/models"
+	"OpenEx-Backend/internal/services/audit"
+	"OpenEx-Backend/internal/services/duplicates"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// duplicateReviewPriority ranks suspected duplicates in the review queue
+const duplicateReviewPriority = 40
+
+// liveListingStatuses are the statuses new listings are compared against for duplicates. A
+// paused service is still its owner's listing, so re-posting it counts as a duplicate.
+var liveListingStatuses = []string{"pending", "needs_review", "approved", "paused"}
+
+// findDuplicateItem compares an item with the owner's recent items and the recent items in
+// the same hostel. The image is only compared once checkItemImage has hashed it.
+func findDuplicateItem(item *models.Item) *duplicates.Match {
+	return duplicates.FindDuplicate(item.Title+" "+item.Description, item.ImageHash, recentItemCandidates(item))
+}
+
+// recentItemCandidates loads the items a new or edited item is compared against
+func recentItemCandidates(item *models.Item) []duplicates.Candidate {
+	var items []models.Item
+	database.DB.Where("id <> ? AND created_at > ? AND status IN ?", item.ID, time.Now().Add(-duplicates.Window()), liveListingStatuses).
+		Where("user_id = ? OR hostel_id = ?", item.UserID, item.HostelID).
+		Order("created_at DESC").
+		Limit(500).
+		Find(&items)
+
+	candidates := make([]duplicates.Candidate, 0, len(items))
+	for _, existing := range items {
+		candidates = append(candidates, duplicates.Candidate{
+			ID:        existing.ID,
+			UserID:    existing.UserID,
+			Text:      existing.Title + " " + existing.Description,
+			ImageHash: existing.ImageHash,
+		})
+	}
+	return candidates
+}
+
+// findDuplicateService compares a new service with the owner's recent services and the
+// recent services in the same hostel
+func findDuplicateService(service *models.Service) *duplicates.Match {
+	var services []models.Service
+	database.DB.Where("created_at > ? AND status IN ?", time.Now().Add(-duplicates.Window()), liveListingStatuses).
+		Where("user_id = ? OR hostel_id = ?", service.UserID, service.HostelID).
+		Order("created_at DESC").
+		Limit(500).
+		Find(&services)
+
+	candidates := make([]duplicates.Candidate, 0, len(services))
+	for _, existing := range services {
+		candidates = append(candidates, duplicates.Candidate{
+			ID:     existing.ID,
+			UserID: existing.UserID,
+			Text:   existing.Title + " " + existing.Description,
+		})
+	}
+
+	return duplicates.FindDuplicate(service.Title+" "+service.Description, "", candidates)
+}
+
+// mergeDuplicateItem adds the quantity of a re-posted item to the owner's existing listing
+// instead of creating a second one
+func mergeDuplicateItem(c *gin.Context, user models.User, match *duplicates.Match, item models.Item) {
+	added := item.Quantity
+	if added <= 0 {
+		added = 1
+	}
+
+	// Added in the database, so concurrent merges all count and nothing else on the listing is
+	// rewritten. A listing that was taken down meanwhile can't be merged into.
+	result := database.DB.Model(&models.Item{}).
+		Where("id = ? AND user_id = ? AND status IN ?", match.ID, user.ID, liveListingStatuses).
+		UpdateColumn("quantity", gorm.Expr("quantity + ?", added))
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create item"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Your matching listing was just taken down; please try again"})
+		return
+	}
+
+	var existing models.Item
+	if err := database.DB.First(&existing, match.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load the merged item"})
+		return
+	}
+
+	audit.Record(audit.UserActor(user), audit.Entry{
+		Action:     "item.merge_duplicate",
+		TargetType: "item",
+		TargetID:   existing.ID,
+		FromStatus: existing.Status,
+		ToStatus:   existing.Status,
+		Reason:     fmt.Sprintf("Added %d to the quantity instead of creating a duplicate", added),
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "You already listed this item, so the quantity was added to your existing listing",
+		"merged":  true,
+		"item":    existing,
+	})
+}
+
+// checkItemImage hashes the image of a new or edited item in the background, since downloading
+// and decoding it is too slow for the request, and sends the item to review if the picture
+// matches another recent listing. Image matches are flagged whatever DUPLICATE_ACTION says,
+// as the item already exists by then.
+func checkItemImage(item models.Item) {
+	hash, err := duplicates.ImageHash(item.Image)
+	if errors.Is(err, duplicates.ErrImageHostNotAllowed) {
+		return
+	}
+	if err != nil {
+		log.Printf("Error hashing image of item #%d: %v", item.ID, err)
+		return
+	}
+
+	// The image condition skips the update if the owner changed the image meanwhile
+	result := database.DB.Model(&models.Item{}).
+		Where("id = ? AND image = ?", item.ID, item.Image).
+		Update("image_hash", hash)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return
+	}
+	item.ImageHash = hash
+
+	// Text duplicates were already handled when the item was submitted, so compare images only
+	match := duplicates.FindDuplicate("", hash, recentItemCandidates(&item))
+	if match == nil {
+		return
+	}
+
+	// Pending and live items go to review; held back or decided ones stay as they are
+	for _, status := range []string{"pending", "approved"} {
+		reason := match.Reason("item")
+		result := database.DB.Model(&models.Item{}).
+			Where("id = ? AND status = ?", item.ID, status).
+			Updates(map[string]interface{}{
+				"status":          "needs_review",
+				"review_reason":   reason,
+				"review_priority": duplicateReviewPriority,
+			})
+		if result.Error == nil && result.RowsAffected > 0 {
+			recordDuplicateFlag("item", item.ID, status, reason)
+			return
+		}
+	}
+}
+
+// recordDuplicateFlag audits a listing that was sent to review as a suspected duplicate
+func recordDuplicateFlag(targetType string, targetID uint, fromStatus, reason string) {
+	audit.Record(audit.WorkerActor("duplicate-detector"), audit.Entry{
+		Action:     targetType + ".flag_duplicate",
+		TargetType: targetType,
+		TargetID:   targetID,
+		FromStatus: fromStatus,
+		ToStatus:   "needs_review",
+		Reason:     reason,
+	})
+}
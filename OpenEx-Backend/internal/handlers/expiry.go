@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"OpenEx-Backend/internal/database"
+	"OpenEx-Backend/internal/models"
+	"OpenEx-Backend/internal/services/audit"
+	"OpenEx-Backend/internal/services/expiry"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RenewTokenRequest is the request payload for renewing from a reminder email link
+type RenewTokenRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// expirableRecord is what renewing needs to know about an item, service or request
+type expirableRecord struct {
+	ID        uint
+	OwnerID   uint
+	Status    string
+	ExpiresAt *time.Time
+}
+
+// RenewItem extends the expiry of the authenticated user's item
+func RenewItem(c *gin.Context) {
+	renewOwned(c, "item")
+}
+
+// RenewService extends the expiry of the authenticated user's service
+func RenewService(c *gin.Context) {
+	renewOwned(c, "service")
+}
+
+// RenewRequestedItem extends the expiry of the authenticated user's requested item
+func RenewRequestedItem(c *gin.Context) {
+	renewOwned(c, "requested_item")
+}
+
+// RenewServiceRequest extends the expiry of the authenticated user's service request
+func RenewServiceRequest(c *gin.Context) {
+	renewOwned(c, "service_request")
+}
+
+// renewOwned renews a record after checking that it belongs to the authenticated user
+func renewOwned(c *gin.Context, targetType string) {
+	user := c.MustGet("user").(models.User)
+	kind, _ := expiry.Lookup(targetType)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	record, err := loadExpirableRecord(kind, uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return
+	}
+
+	if record.OwnerID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the owner can renew this " + kind.Label})
+		return
+	}
+
+	renewRecord(c, user, kind, record)
+}
+
+// RenewWithToken renews the record named in a reminder email's renew link, without logging in
+func RenewWithToken(c *gin.Context) {
+	var req RenewTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	targetType, targetID, issuedFor, err := expiry.ParseRenewToken(req.Token)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired renew link"})
+		return
+	}
+
+	kind, ok := expiry.Lookup(targetType)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired renew link"})
+		return
+	}
+
+	record, err := loadExpirableRecord(kind, targetID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return
+	}
+
+	// A link only works for the expiry it was sent about, so it can't be replayed forever
+	if record.ExpiresAt == nil || record.ExpiresAt.Unix() != issuedFor {
+		c.JSON(http.StatusConflict, gin.H{"error": "This " + kind.Label + " has already been renewed", "expiresAt": record.ExpiresAt})
+		return
+	}
+
+	var owner models.User
+	if err := database.DB.First(&owner, record.OwnerID).Error; err != nil || owner.AnonymizedAt != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not found"})
+		return
+	}
+
+	renewRecord(c, owner, kind, record)
+}
+
+// loadExpirableRecord loads the fields renewing needs from the kind's table
+func loadExpirableRecord(kind expiry.Kind, id uint) (expirableRecord, error) {
+	var record expirableRecord
+	err := database.DB.Table(kind.Table).
+		Select("id, "+kind.OwnerColumn+" AS owner_id, status, expires_at").
+		Where("id = ?", id).
+		Take(&record).Error
+	return record, err
+}
+
+// renewRecord restarts the expiry clock of a live or expired record and republishes it if it
+// had expired
+func renewRecord(c *gin.Context, user models.User, kind expiry.Kind, record expirableRecord) {
+	ttl := kind.TTL()
+	if ttl <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Records of this type do not expire"})
+		return
+	}
+
+	if record.Status != kind.ActiveStatus && record.Status != expiry.StatusExpired {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only live or expired records can be renewed", "status": record.Status})
+		return
+	}
+
+	// The status condition leaves a record that was closed, removed or expired meanwhile alone
+	expiresAt := time.Now().Add(ttl)
+	result := database.DB.Table(kind.Table).Where("id = ? AND status = ?", record.ID, record.Status).
+		Updates(map[string]interface{}{
+			"status":             kind.ActiveStatus,
+			"expires_at":         expiresAt,
+			"expiry_reminded_at": nil,
+			"updated_at":         time.Now(),
+		})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to renew"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "This record changed meanwhile; reload it and try again"})
+		return
+	}
+
+	audit.Record(audit.UserActor(user), audit.Entry{
+		Action:     kind.TargetType + ".renew",
+		TargetType: kind.TargetType,
+		TargetID:   record.ID,
+		FromStatus: record.Status,
+		ToStatus:   kind.ActiveStatus,
+		Reason:     "Renewed until " + expiresAt.Format("2006-01-02"),
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Renewed successfully",
+		"status":    kind.ActiveStatus,
+		"expiresAt": expiresAt,
+	})
+}
@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
 
 	"OpenEx-Backend/internal/database"
 	"OpenEx-Backend/internal/models"
+	"OpenEx-Backend/internal/services/audit"
 
 	"github.com/gin-gonic/gin"
 )
@@ -13,7 +15,7 @@ func ListAllItems(c *gin.Context) {
 	var items []models.Item
 
 	// Fetch all approved items regardless of hostel
-	result := database.DB.Preload("Hostel").Where("status = ?", "approved").Find(&items)
+	result := database.DB.Preload("Hostel").Scopes(visibleOwner("user_id")).Where("status = ?", "approved").Find(&items)
 	if result.Error != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch items"})
 		return
@@ -33,10 +35,13 @@ func ListHostels(c *gin.Context) {
 // HostelRequest is the request payload for creating a hostel
 type HostelRequest struct {
 	Name string `json:"name" binding:"required"`
+	Zone string `json:"zone"`
 }
 
 // CreateHostel creates a new hostel
 func CreateHostel(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
 	var req HostelRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -52,8 +57,17 @@ func CreateHostel(c *gin.Context) {
 
 	hostel := models.Hostel{
 		Name: req.Name,
+		Zone: strings.TrimSpace(req.Zone),
 	}
 
 	database.DB.Create(&hostel)
+
+	audit.Record(audit.UserActor(user), audit.Entry{
+		Action:     "hostel.create",
+		TargetType: "hostel",
+		TargetID:   hostel.ID,
+		Reason:     hostel.Name,
+	})
+
 	c.JSON(http.StatusCreated, hostel)
 }
@@ -1,11 +1,21 @@
 package handlers
 
 import (
+	"errors"
+	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"OpenEx-Backend/internal/database"
 	"OpenEx-Backend/internal/models"
+	"OpenEx-Backend/internal/rbac"
+	"OpenEx-Backend/internal/services/audit"
+	"OpenEx-Backend/internal/services/duplicates"
+	"OpenEx-Backend/internal/services/matcher"
+	"OpenEx-Backend/internal/services/moderationlog"
+	"OpenEx-Backend/internal/services/notify"
+	"OpenEx-Backend/internal/worker"
 
 	"github.com/gin-gonic/gin"
 )
@@ -55,6 +65,7 @@ func CreateItem(c *gin.Context) {
 		return
 	}
 
+	now := time.Now()
 	item := models.Item{
 		Title:       req.Title,
 		Description: req.Description,
@@ -65,6 +76,27 @@ func CreateItem(c *gin.Context) {
 		UserID:      user.ID,
 		HostelID:    req.HostelID, // Add this field
 		Status:      "pending",
+		SubmittedAt: &now,
+	}
+
+	// Re-posting the same item is handled according to DUPLICATE_ACTION
+	match := findDuplicateItem(&item)
+	if match != nil {
+		switch duplicates.Action() {
+		case duplicates.ActionBlock:
+			c.JSON(http.StatusConflict, gin.H{"error": "This looks like a duplicate of an existing listing", "duplicate_of": match.ID})
+			return
+		case duplicates.ActionMerge:
+			if match.UserID == user.ID {
+				mergeDuplicateItem(c, user, match, item)
+				return
+			}
+			fallthrough // Someone else's listing can't be merged into, so let a moderator decide
+		default:
+			item.Status = "needs_review"
+			item.ReviewReason = match.Reason("item")
+			item.ReviewPriority = duplicateReviewPriority
+		}
 	}
 
 	if err := database.DB.Create(&item).Error; err != nil {
@@ -72,6 +104,15 @@ func CreateItem(c *gin.Context) {
 		return
 	}
 
+	if item.Status == "needs_review" {
+		recordDuplicateFlag("item", item.ID, "pending", item.ReviewReason)
+	} else {
+		worker.ItemSubmitted(&item)
+	}
+	if item.Image != "" {
+		go checkItemImage(item)
+	}
+
 	c.JSON(http.StatusCreated, item)
 }
 
@@ -79,7 +120,9 @@ func CreateItem(c *gin.Context) {
 func GetItem(c *gin.Context) {
 	var item models.Item
 
-	if err := database.DB.Preload("User").Preload("Hostel").First(&item, c.Param("id")).Error; err != nil {
+	// Hidden items and items of restricted owners are as invisible here as in the lists
+	if err := database.DB.Scopes(visibleOwner("user_id")).
+		Preload("User").Preload("Hostel").First(&item, c.Param("id")).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
 		return
 	}
@@ -108,6 +151,11 @@ func GetItem(c *gin.Context) {
 		"is_favorite": isFavorite,
 	}
 
+	// Only the owner gets to see why their item was rejected
+	if user, exists := c.Get("user"); exists && user.(models.User).ID == item.UserID && item.Status == "rejected" {
+		response["rejection_reason"] = item.RejectionReason
+	}
+
 	c.JSON(http.StatusOK, response)
 }
 
@@ -116,7 +164,7 @@ func ListItems(c *gin.Context) {
 	var items []models.Item
 
 	// Add status filter for approved items only
-	if err := database.DB.Preload("Hostel").Where("status = ?", "approved").Find(&items).Error; err != nil {
+	if err := database.DB.Preload("Hostel").Scopes(visibleOwner("user_id")).Where("status = ?", "approved").Find(&items).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch items"})
 		return
 	}
@@ -149,7 +197,7 @@ func ListItemsByHostel(c *gin.Context) {
 	var items []models.Item
 
 	// Add status filter for approved items only
-	if err := database.DB.Preload("Hostel").Where("hostel_id = ? AND status = ?", hostelID, "approved").Find(&items).Error; err != nil {
+	if err := database.DB.Preload("Hostel").Scopes(visibleOwner("user_id")).Where("hostel_id = ? AND status = ?", hostelID, "approved").Find(&items).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch items"})
 		return
 	}
@@ -184,27 +232,141 @@ func GetUserItems(c *gin.Context) {
 	c.JSON(http.StatusOK, items)
 }
 
-// ListPendingItems returns all pending items (admin only)
+// ListPendingItems returns items waiting for moderation, highest review priority first
+// (staff only, limited to the moderator's hostel)
 func ListPendingItems(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	statuses, ok := reviewQueueStatuses(c.Query("queue"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "queue must be pending, review or all"})
+		return
+	}
+
+	query := database.DB.Where("status IN ?", statuses).Scopes(reviewQueueOrder)
+	if hostelID := rbac.HostelScope(user); hostelID != nil {
+		query = query.Where("hostel_id = ?", *hostelID)
+	}
+
 	var items []models.Item
-	database.DB.Where("status = 'pending'").Find(&items)
+	query.Find(&items)
 	c.JSON(http.StatusOK, items)
 }
 
-// ApproveItem approves a pending item (admin only)
+// ApproveItem approves a pending item (moderators only)
 func ApproveItem(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
 	var item models.Item
-	database.DB.First(&item, c.Param("id"))
+	if err := database.DB.First(&item, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+		return
+	}
+
+	if !rbac.CanAccessHostel(user, item.HostelID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only moderate items in your hostel"})
+		return
+	}
+
+	// The status condition keeps a listing decided, expired or withdrawn meanwhile untouched
+	previousStatus := item.Status
+	result := database.DB.Model(&models.Item{}).
+		Where("id = ? AND status IN ?", item.ID, moderatableStatuses).
+		Updates(map[string]interface{}{
+			"status":           "approved",
+			"rejection_reason": "",
+			"review_reason":    "",
+			"review_priority":  0,
+		})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to approve item"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only pending or needs_review items can be approved", "status": previousStatus})
+		return
+	}
 	item.Status = "approved"
-	database.DB.Save(&item)
+	item.RejectionReason = ""
+	item.ReviewReason = ""
+	item.ReviewPriority = 0
+
+	audit.Record(audit.UserActor(user), audit.Entry{
+		Action:     "item.approve",
+		TargetType: "item",
+		TargetID:   item.ID,
+		FromStatus: previousStatus,
+		ToStatus:   item.Status,
+	})
+	moderationlog.RecordHuman("item", item.ID, user, "approved", "")
+
+	go matcher.ItemApproved(item)
+
 	c.JSON(http.StatusOK, item)
 }
 
-// RejectItem rejects a pending item (admin only)
+// RejectItem rejects a pending item with a reason shown to the owner (moderators only)
 func RejectItem(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	// The reason is optional; an empty body falls back to the default reason
+	var req RejectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		req.Reason = defaultRejectionReason
+	}
+
 	var item models.Item
-	database.DB.First(&item, c.Param("id"))
+	if err := database.DB.First(&item, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+		return
+	}
+
+	if !rbac.CanAccessHostel(user, item.HostelID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only moderate items in your hostel"})
+		return
+	}
+
+	// The status condition keeps a listing decided, expired or withdrawn meanwhile untouched
+	previousStatus := item.Status
+	result := database.DB.Model(&models.Item{}).
+		Where("id = ? AND status IN ?", item.ID, moderatableStatuses).
+		Updates(map[string]interface{}{
+			"status":           "rejected",
+			"rejection_reason": strings.TrimSpace(req.Reason),
+			"review_reason":    "",
+			"review_priority":  0,
+		})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reject item"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only pending or needs_review items can be rejected", "status": previousStatus})
+		return
+	}
 	item.Status = "rejected"
-	database.DB.Save(&item)
+	item.RejectionReason = strings.TrimSpace(req.Reason)
+	item.ReviewReason = ""
+	item.ReviewPriority = 0
+
+	audit.Record(audit.UserActor(user), audit.Entry{
+		Action:     "item.reject",
+		TargetType: "item",
+		TargetID:   item.ID,
+		FromStatus: previousStatus,
+		ToStatus:   item.Status,
+		Reason:     item.RejectionReason,
+	})
+	moderationlog.RecordHuman("item", item.ID, user, "rejected", item.RejectionReason)
+
+	var owner models.User
+	if err := database.DB.First(&owner, item.UserID).Error; err == nil {
+		go notify.ListingRejected(owner, "item", item.Title, item.RejectionReason)
+	}
+
 	c.JSON(http.StatusOK, item)
 }
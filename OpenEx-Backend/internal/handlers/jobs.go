@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"OpenEx-Backend/internal/scheduler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListJobs returns the background jobs with their run counts and timings (staff only)
+func ListJobs(c *gin.Context) {
+	results := []gin.H{}
+	for _, job := range scheduler.Default.Stats() {
+		var averageMs int64
+		if job.Runs > 0 {
+			averageMs = (job.TotalDuration / time.Duration(job.Runs)).Milliseconds()
+		}
+
+		results = append(results, gin.H{
+			"name":           job.Name,
+			"intervalSecs":   int64(job.Interval.Seconds()),
+			"running":        job.Running,
+			"runs":           job.Runs,
+			"failures":       job.Failures,
+			"panics":         job.Panics,
+			"skipped":        job.Skipped,
+			"lockMissed":     job.LockMissed,
+			"lastStart":      job.LastStart,
+			"lastDurationMs": job.LastDuration.Milliseconds(),
+			"avgDurationMs":  averageMs,
+			"maxDurationMs":  job.MaxDuration.Milliseconds(),
+			"lastError":      job.LastError,
+		})
+	}
+	c.JSON(http.StatusOK, results)
+}
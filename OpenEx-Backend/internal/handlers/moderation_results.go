@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"OpenEx-Backend/internal/database"
+	"OpenEx-Backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// scoreBucketStats counts how content with provider scores in one range was decided
+type scoreBucketStats struct {
+	Total            int `json:"total"`
+	ProviderRejected int `json:"provider_rejected"`
+	ProviderFlagged  int `json:"provider_flagged"`
+	HumanApproved    int `json:"human_approved"`
+	HumanRejected    int `json:"human_rejected"`
+}
+
+// ListModerationResults returns stored moderation decisions with provider scores (staff only)
+func ListModerationResults(c *gin.Context) {
+	query := database.DB.Model(&models.ModerationResult{}).Preload("Providers")
+
+	if targetType := c.Query("target_type"); targetType != "" {
+		query = query.Where("target_type = ?", targetType)
+	}
+	if targetID := c.Query("target_id"); targetID != "" {
+		query = query.Where("target_id = ?", targetID)
+	}
+	if source := c.Query("source"); source != "" {
+		query = query.Where("source = ?", source)
+	}
+	if decision := c.Query("decision"); decision != "" {
+		query = query.Where("decision = ?", decision)
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	var results []models.ModerationResult
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&results).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch moderation results"})
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// defaultStatsWindow is how far back GetModerationStats looks when no ?since= is given
+const defaultStatsWindow = 30 * 24 * time.Hour
+
+// statsSince parses the ?since= parameter as an RFC 3339 time or a YYYY-MM-DD date
+func statsSince(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Now().Add(-defaultStatsWindow), true
+	}
+	if since, err := time.Parse(time.RFC3339, value); err == nil {
+		return since, true
+	}
+	if since, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return since, true
+	}
+	return time.Time{}, false
+}
+
+// GetModerationStats summarizes provider scores against final human decisions, to help tune
+// MODERATION_THRESHOLDS (staff only). Safety scores are grouped into 0.1 wide buckets. Only
+// decisions since ?since= (default the last 30 days) are counted.
+func GetModerationStats(c *gin.Context) {
+	targetType := c.Query("target_type")
+
+	since, ok := statsSince(c.Query("since"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "since must be an RFC 3339 time or a YYYY-MM-DD date"})
+		return
+	}
+
+	// The latest human decision for each target is treated as the ground truth
+	var humanResults []models.ModerationResult
+	humanQuery := database.DB.Where("source = ? AND created_at >= ?", "human", since).Order("created_at, id")
+	if targetType != "" {
+		humanQuery = humanQuery.Where("target_type = ?", targetType)
+	}
+	if err := humanQuery.Find(&humanResults).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch moderation results"})
+		return
+	}
+	humanDecision := map[string]string{}
+	for _, result := range humanResults {
+		humanDecision[fmt.Sprintf("%s:%d", result.TargetType, result.TargetID)] = result.Decision
+	}
+
+	var automaticResults []models.ModerationResult
+	automaticQuery := database.DB.Preload("Providers").Where("source = ? AND created_at >= ?", "automatic", since)
+	if targetType != "" {
+		automaticQuery = automaticQuery.Where("target_type = ?", targetType)
+	}
+	if err := automaticQuery.Find(&automaticResults).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch moderation results"})
+		return
+	}
+
+	decisions := map[string]int{}
+	overturned := 0
+	providerStats := map[string]map[string]*scoreBucketStats{}
+	providerFailures := map[string]int{}
+
+	for _, result := range automaticResults {
+		decisions[result.Decision]++
+
+		human, reviewed := humanDecision[fmt.Sprintf("%s:%d", result.TargetType, result.TargetID)]
+		if reviewed && (result.Decision == "approved" || result.Decision == "rejected") && human != result.Decision {
+			overturned++
+		}
+
+		for _, score := range result.Providers {
+			if score.Verdict == "error" {
+				providerFailures[score.Provider]++
+				continue
+			}
+			if score.Verdict == "skipped" {
+				continue
+			}
+
+			bucket := math.Min(math.Floor(score.Score*10)/10, 0.9)
+			label := fmt.Sprintf("%.1f-%.1f", bucket, bucket+0.1)
+
+			if providerStats[score.Provider] == nil {
+				providerStats[score.Provider] = map[string]*scoreBucketStats{}
+			}
+			stats := providerStats[score.Provider][label]
+			if stats == nil {
+				stats = &scoreBucketStats{}
+				providerStats[score.Provider][label] = stats
+			}
+
+			stats.Total++
+			switch score.Verdict {
+			case "rejected":
+				stats.ProviderRejected++
+			case "flagged":
+				stats.ProviderFlagged++
+			}
+			switch human {
+			case "approved":
+				stats.HumanApproved++
+			case "rejected":
+				stats.HumanRejected++
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"since":               since,
+		"automatic_decisions": decisions,
+		"human_overturned":    overturned,
+		"provider_scores":     providerStats,
+		"provider_failures":   providerFailures,
+	})
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"OpenEx-Backend/internal/models"
+	"OpenEx-Backend/internal/services/audit"
+	"OpenEx-Backend/internal/services/moderator"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestModerationRequest is the request payload for trying text against the keyword rules
+type TestModerationRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// GetModerationRules returns the active keyword moderation rules (staff only)
+func GetModerationRules(c *gin.Context) {
+	c.JSON(http.StatusOK, moderator.CurrentRules())
+}
+
+// UpdateModerationRules replaces the keyword moderation rules at runtime (admin only).
+// If MODERATION_RULES_FILE is set the new rules are also written there so they survive a restart.
+func UpdateModerationRules(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var req moderator.RuleSet
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := moderator.SetRules(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	persisted := false
+	if path := moderator.RulesFile(); path != "" {
+		if err := moderator.SaveRulesFile(path); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Rules are active but could not be saved: " + err.Error()})
+			return
+		}
+		persisted = true
+	}
+
+	audit.Record(audit.UserActor(user), audit.Entry{
+		Action:     "moderation_rules.update",
+		TargetType: "moderation_rules",
+		Reason:     fmt.Sprintf("%d rules, %d categories, %d allow-listed phrases", len(req.Rules), len(req.Categories), len(req.Allow)),
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"rules":     moderator.CurrentRules(),
+		"persisted": persisted,
+	})
+}
+
+// TestModerationRules shows how the active keyword rules judge a title and description (staff only)
+func TestModerationRules(c *gin.Context) {
+	var req TestModerationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, moderator.ModerateText(req.Title, req.Description))
+}
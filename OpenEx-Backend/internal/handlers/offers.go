@@ -0,0 +1,554 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"OpenEx-Backend/internal/database"
+	"OpenEx-Backend/internal/models"
+	"OpenEx-Backend/internal/rbac"
+	"OpenEx-Backend/internal/services/audit"
+	"OpenEx-Backend/internal/services/moderator"
+	"OpenEx-Backend/internal/services/notify"
+	"OpenEx-Backend/internal/services/offers"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// OfferRequest is the request payload for making an offer on a requested item
+type OfferRequest struct {
+	Price    float64 `json:"price" binding:"min=0"`
+	Quantity int     `json:"quantity"`
+	Message  string  `json:"message" binding:"required"`
+	Image    string  `json:"image"`
+}
+
+// AcceptOfferRequest is the optional request payload for accepting part of an offer
+type AcceptOfferRequest struct {
+	Quantity int `json:"quantity" binding:"omitempty,min=1"`
+}
+
+// activeOfferStatuses are the statuses of offers that are still in play
+var activeOfferStatuses = offers.ActiveStatuses
+
+// errOfferUnavailable is returned when an offer can no longer be accepted
+var errOfferUnavailable = errors.New("offer is no longer available")
+
+// CreateOffer lets a seller offer something for another user's requested item. The offer is
+// moderated before the buyer sees it.
+func CreateOffer(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	// Accepting an offer starts a transaction, which needs a way to reach the seller
+	if user.ContactDetails == "" || strings.Contains(user.ContactDetails, "@") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Please update your phone number in contact details before making an offer"})
+		return
+	}
+
+	var req OfferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Quantity <= 0 {
+		req.Quantity = 1
+	}
+
+	var requestedItem models.RequestedItem
+	if err := database.DB.Preload("Buyer").First(&requestedItem, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Requested item not found"})
+		return
+	}
+
+	if requestedItem.BuyerID == user.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot make an offer on your own request"})
+		return
+	}
+
+	if requestedItem.Status != "open" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This request is no longer open"})
+		return
+	}
+
+	if remaining := requestedItem.RemainingQuantity(); req.Quantity > remaining {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("The buyer only needs %d more", remaining), "remaining": remaining})
+		return
+	}
+
+	var existing int64
+	database.DB.Model(&models.Offer{}).
+		Where("requested_item_id = ? AND seller_id = ? AND status IN ?", requestedItem.ID, user.ID, activeOfferStatuses).
+		Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "You already have an offer on this request; withdraw it to make a new one"})
+		return
+	}
+
+	offer := models.Offer{
+		RequestedItemID: requestedItem.ID,
+		SellerID:        user.ID,
+		HostelID:        user.HostelID,
+		Price:           req.Price,
+		Quantity:        req.Quantity,
+		Message:         strings.TrimSpace(req.Message),
+		Image:           req.Image,
+		Status:          "pending",
+	}
+	if err := database.DB.Create(&offer).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create offer"})
+		return
+	}
+
+	if moderateOffer(&offer, requestedItem, user) && offer.Status == "open" {
+		go sendOfferReceivedEmail(requestedItem.Buyer, user, offer, requestedItem)
+	}
+
+	c.JSON(http.StatusCreated, offer)
+}
+
+// moderateOffer runs the offer through the offer pipeline. Clean offers open right away,
+// borderline ones wait for a moderator and offending ones are rejected.
+func moderateOffer(offer *models.Offer, requestedItem models.RequestedItem, seller models.User) bool {
+	return moderateSubmission(offerSubmission(offer, requestedItem, seller), moderator.Content{
+		Title:       requestedItem.Title,
+		Description: offer.Message,
+		ImageURL:    offer.Image,
+	})
+}
+
+// offerSubmission describes an offer for the shared moderation helpers
+func offerSubmission(offer *models.Offer, requestedItem models.RequestedItem, seller models.User) submission {
+	return submission{
+		Row:      offer,
+		Kind:     moderator.KindOffer,
+		Label:    "Offer",
+		ID:       offer.ID,
+		HostelID: offer.HostelID,
+		Status:   offer.Status,
+		Notify: func(reason string) {
+			notify.OfferRejected(seller, requestedItem.Title, reason)
+		},
+	}
+}
+
+// offerSummary is the view of an offer shown to the buyer and the seller
+func offerSummary(offer models.Offer) gin.H {
+	return gin.H{
+		"id":                   offer.ID,
+		"requestedItemId":      offer.RequestedItemID,
+		"requestTitle":         offer.RequestedItem.Title,
+		"sellerId":             offer.SellerID,
+		"seller":               offer.Seller.Name,
+		"price":                offer.Price,
+		"quantity":             offer.Quantity,
+		"message":              offer.Message,
+		"image":                offer.Image,
+		"status":               offer.Status,
+		"rejectionReason":      offer.RejectionReason,
+		"itemId":               offer.ItemID,
+		"transactionRequestId": offer.TransactionRequestID,
+		"createdAt":            offer.CreatedAt,
+	}
+}
+
+// ListOffers returns the offers on a requested item that passed moderation, cheapest first (buyer only)
+func ListOffers(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var requestedItem models.RequestedItem
+	if err := database.DB.First(&requestedItem, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Requested item not found"})
+		return
+	}
+
+	if requestedItem.BuyerID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the buyer can see the offers on this request"})
+		return
+	}
+
+	var offers []models.Offer
+	database.DB.Preload("Seller").Preload("RequestedItem").
+		Where("requested_item_id = ? AND status IN ?", requestedItem.ID, []string{"open", "accepted", "declined"}).
+		Order("price, created_at").
+		Find(&offers)
+
+	results := []gin.H{}
+	for _, offer := range offers {
+		results = append(results, offerSummary(offer))
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// GetMyOffers returns the offers made by the authenticated user
+func GetMyOffers(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var offers []models.Offer
+	database.DB.Preload("Seller").Preload("RequestedItem").
+		Where("seller_id = ?", user.ID).
+		Order("created_at DESC").
+		Find(&offers)
+
+	results := []gin.H{}
+	for _, offer := range offers {
+		results = append(results, offerSummary(offer))
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// AcceptOffer accepts an offer on the buyer's request. A listing reserved for the buyer and a
+// pending transaction request are created, and the seller confirms it through the usual
+// request flow. The buyer may take fewer than offered; the request stays open until the
+// accepted offers cover its quantity, and only then are the other offers declined.
+func AcceptOffer(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var req AcceptOfferRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var offer models.Offer
+	if err := database.DB.Preload("Seller").Preload("RequestedItem").First(&offer, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Offer not found"})
+		return
+	}
+
+	if offer.RequestedItem.BuyerID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the buyer can accept this offer"})
+		return
+	}
+
+	if offer.Status != "open" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only open offers can be accepted", "status": offer.Status})
+		return
+	}
+
+	if offer.Seller.AnonymizedAt != nil || offer.Seller.IsBanned() || offer.Seller.IsSuspended() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This seller is currently unavailable"})
+		return
+	}
+
+	requestedItem := offer.RequestedItem
+	quantity := offer.Quantity
+	if req.Quantity > 0 {
+		if req.Quantity > offer.Quantity {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("The seller only offered %d", offer.Quantity)})
+			return
+		}
+		quantity = req.Quantity
+	} else if remaining := requestedItem.RemainingQuantity(); remaining > 0 && quantity > remaining {
+		quantity = remaining
+	}
+
+	var item models.Item
+	var tr models.TransactionRequest
+	var declined []models.Offer
+	fulfilled := false
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Only one accept of an offer wins, even if the buyer double-clicks, and an offer
+		// withdrawn meanwhile stays withdrawn
+		result := tx.Model(&models.Offer{}).Where("id = ? AND status = ?", offer.ID, "open").
+			Updates(map[string]interface{}{"status": "accepted", "quantity": quantity})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errOfferUnavailable
+		}
+
+		// The quantity condition keeps concurrent accepts from covering more than was asked for
+		result = tx.Model(&models.RequestedItem{}).
+			Where("id = ? AND status = ? AND quantity - fulfilled_quantity >= ?", requestedItem.ID, "open", quantity).
+			Update("fulfilled_quantity", gorm.Expr("fulfilled_quantity + ?", quantity))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errOfferUnavailable
+		}
+
+		result = tx.Model(&models.RequestedItem{}).
+			Where("id = ? AND fulfilled_quantity >= quantity", requestedItem.ID).
+			Update("status", "fulfilled")
+		if result.Error != nil {
+			return result.Error
+		}
+		fulfilled = result.RowsAffected > 0
+
+		// The offer's content was moderated, so the listing starts out approved. It is reserved
+		// for the buyer and never shows up in public lists.
+		now := time.Now()
+		item = models.Item{
+			UserID:      offer.SellerID,
+			HostelID:    offer.HostelID,
+			Title:       requestedItem.Title,
+			Description: offer.Message,
+			Price:       offer.Price,
+			Image:       offer.Image,
+			Quantity:    quantity,
+			Type:        "sell",
+			Status:      "reserved",
+			SubmittedAt: &now,
+		}
+		if err := tx.Create(&item).Error; err != nil {
+			return err
+		}
+
+		tr = models.TransactionRequest{
+			BuyerID:  user.ID,
+			SellerID: offer.SellerID,
+			ItemID:   item.ID,
+			Type:     "buy",
+			Quantity: quantity,
+			Status:   "pending",
+		}
+		if err := tx.Create(&tr).Error; err != nil {
+			return err
+		}
+
+		offer.Status = "accepted"
+		offer.Quantity = quantity
+		offer.ItemID = &item.ID
+		offer.TransactionRequestID = &tr.ID
+		if err := tx.Model(&models.Offer{}).Where("id = ?", offer.ID).Updates(map[string]interface{}{
+			"item_id":                item.ID,
+			"transaction_request_id": tr.ID,
+		}).Error; err != nil {
+			return err
+		}
+
+		// Other offers stay open while the buyer still needs more
+		if !fulfilled {
+			return nil
+		}
+		if err := tx.Preload("Seller").
+			Where("requested_item_id = ? AND id <> ? AND status IN ?", requestedItem.ID, offer.ID, activeOfferStatuses).
+			Find(&declined).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Offer{}).
+			Where("requested_item_id = ? AND id <> ? AND status IN ?", requestedItem.ID, offer.ID, activeOfferStatuses).
+			Update("status", "declined").Error
+	})
+	if errors.Is(err, errOfferUnavailable) {
+		var currentOffer models.Offer
+		if database.DB.First(&currentOffer, offer.ID).Error == nil && currentOffer.Status != "open" {
+			c.JSON(http.StatusConflict, gin.H{"error": "This offer was accepted or withdrawn meanwhile", "status": currentOffer.Status})
+			return
+		}
+		var current models.RequestedItem
+		if database.DB.First(&current, requestedItem.ID).Error == nil && current.Status == "open" {
+			c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("The buyer only needs %d more", current.RemainingQuantity()), "remaining": current.RemainingQuantity()})
+			return
+		}
+		c.JSON(http.StatusConflict, gin.H{"error": "This request is no longer open"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept offer"})
+		return
+	}
+
+	audit.Record(audit.UserActor(user), audit.Entry{
+		Action:     "offer.accept",
+		TargetType: "offer",
+		TargetID:   offer.ID,
+		FromStatus: "open",
+		ToStatus:   offer.Status,
+		Reason:     fmt.Sprintf("Transaction request #%d created for %d", tr.ID, quantity),
+	})
+
+	go sendSellerNotificationEmail(offer.Seller, user, item, tr)
+	for _, other := range declined {
+		go notify.OfferDeclined(other.Seller, requestedItem.Title, "The buyer accepted another offer.")
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Offer accepted. The seller has been asked to confirm the transaction",
+		"offer":   offerSummary(offer),
+		"request": tr,
+	})
+}
+
+// DeclineOffer turns down a single offer on the buyer's request
+func DeclineOffer(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var offer models.Offer
+	if err := database.DB.Preload("Seller").Preload("RequestedItem").First(&offer, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Offer not found"})
+		return
+	}
+
+	if offer.RequestedItem.BuyerID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the buyer can decline this offer"})
+		return
+	}
+
+	if offer.Status != "open" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only open offers can be declined", "status": offer.Status})
+		return
+	}
+
+	// The status condition keeps an offer accepted or withdrawn meanwhile as it is
+	result := database.DB.Model(&models.Offer{}).
+		Where("id = ? AND status = ?", offer.ID, "open").
+		Update("status", "declined")
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decline offer"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "This offer was accepted or withdrawn meanwhile"})
+		return
+	}
+	offer.Status = "declined"
+
+	go notify.OfferDeclined(offer.Seller, offer.RequestedItem.Title, "")
+
+	c.JSON(http.StatusOK, offerSummary(offer))
+}
+
+// WithdrawOffer lets the seller take back an offer the buyer has not accepted yet
+func WithdrawOffer(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var offer models.Offer
+	if err := database.DB.Preload("Seller").Preload("RequestedItem").First(&offer, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Offer not found"})
+		return
+	}
+
+	if offer.SellerID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the seller can withdraw this offer"})
+		return
+	}
+
+	result := database.DB.Model(&models.Offer{}).
+		Where("id = ? AND status IN ?", offer.ID, activeOfferStatuses).
+		Update("status", "withdrawn")
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to withdraw offer"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This offer can no longer be withdrawn", "status": offer.Status})
+		return
+	}
+
+	offer.Status = "withdrawn"
+	c.JSON(http.StatusOK, offerSummary(offer))
+}
+
+// ListOffersForReview returns the offers automatic moderation handed to a human, highest
+// priority first (staff only, limited to the moderator's hostel)
+func ListOffersForReview(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	query := database.DB.Preload("Seller").Preload("RequestedItem").
+		Where("status = ?", "needs_review").
+		Order("review_priority DESC").Order("created_at")
+	if hostelID := rbac.HostelScope(user); hostelID != nil {
+		query = query.Where("hostel_id = ?", *hostelID)
+	}
+
+	var offers []models.Offer
+	query.Find(&offers)
+
+	results := []gin.H{}
+	for _, offer := range offers {
+		summary := offerSummary(offer)
+		summary["reviewReason"] = offer.ReviewReason
+		summary["reviewPriority"] = offer.ReviewPriority
+		results = append(results, summary)
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// ApproveOffer shows a held back offer to the buyer (moderators only)
+func ApproveOffer(c *gin.Context) {
+	decideOffer(c, true)
+}
+
+// RejectOffer rejects a held back offer with a reason shown to the seller (moderators only)
+func RejectOffer(c *gin.Context) {
+	decideOffer(c, false)
+}
+
+// decideOffer records a moderator's decision on an offer waiting for review
+func decideOffer(c *gin.Context, approved bool) {
+	var offer models.Offer
+	if err := database.DB.Preload("Seller").Preload("RequestedItem.Buyer").First(&offer, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Offer not found"})
+		return
+	}
+
+	if !decideSubmission(c, offerSubmission(&offer, offer.RequestedItem, offer.Seller), approved) {
+		return
+	}
+
+	// The request may have been closed while the offer waited; the buyer then no longer cares
+	if approved && offer.RequestedItem.Status == "open" {
+		go sendOfferReceivedEmail(offer.RequestedItem.Buyer, offer.Seller, offer, offer.RequestedItem)
+	}
+
+	c.JSON(http.StatusOK, offerSummary(offer))
+}
+
+// declineActiveOffers declines the active offers on a requested item that is no longer taking
+// them and tells their sellers why
+func declineActiveOffers(requestedItemID uint, reason string) {
+	if err := offers.DeclineActive(requestedItemID, reason); err != nil {
+		log.Printf("Error declining the active offers on requested item #%d: %v", requestedItemID, err)
+	}
+}
+
+// releaseDeclinedTransaction reopens the requested item behind an accepted offer when the
+// seller turns the resulting transaction request down, so other sellers can make offers again
+func releaseDeclinedTransaction(tr models.TransactionRequest) {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return releaseOfferReservation(tx, tr)
+	})
+	if err != nil {
+		log.Printf("Error releasing the offer behind transaction #%d after it was rejected: %v", tr.ID, err)
+	}
+}
+
+// releaseOfferReservation cancels the accepted offer behind a transaction request that will
+// not go ahead and removes its reserved item. Anything cancelling a pending transaction
+// request runs it in the same database transaction.
+func releaseOfferReservation(tx *gorm.DB, tr models.TransactionRequest) error {
+	var offer models.Offer
+	err := tx.Where("transaction_request_id = ? AND status = ?", tr.ID, "accepted").First(&offer).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil // Not created from an offer
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Model(&offer).Update("status", "cancelled").Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.Item{}).Where("id = ? AND status = ?", tr.ItemID, "reserved").
+		Update("status", "removed").Error; err != nil {
+		return err
+	}
+	// Give the quantity back and reopen the request if that leaves it short
+	if err := tx.Model(&models.RequestedItem{}).Where("id = ?", offer.RequestedItemID).
+		Update("fulfilled_quantity", gorm.Expr("GREATEST(fulfilled_quantity - ?, 0)", tr.Quantity)).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.RequestedItem{}).
+		Where("id = ? AND status = ? AND fulfilled_quantity < quantity", offer.RequestedItemID, "fulfilled").
+		Update("status", "open").Error
+}
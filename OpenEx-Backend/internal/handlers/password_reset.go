@@ -12,6 +12,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
 
+	"OpenEx-Backend/internal/config"
 	"OpenEx-Backend/internal/database"
 	"OpenEx-Backend/internal/models"
 )
@@ -66,14 +67,8 @@ func ForgotPassword(c *gin.Context) {
 		return
 	}
 
-	// Get the frontend URL from environment variable or use a default
-	frontendURL := os.Getenv("FRONTEND_URL")
-	if frontendURL == "" {
-		frontendURL = "http://localhost:5173"
-	}
-
 	// Construct the reset URL that the user will receive
-	resetURL := fmt.Sprintf("%s/reset-password?token=%s", frontendURL, token)
+	resetURL := fmt.Sprintf("%s/reset-password?token=%s", config.FrontendURL(), token)
 
 	// Send the email with the reset link
 	if err := sendResetEmail(user.Email, resetURL); err != nil {
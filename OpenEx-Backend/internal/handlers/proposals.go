@@ -0,0 +1,348 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"OpenEx-Backend/internal/database"
+	"OpenEx-Backend/internal/models"
+	"OpenEx-Backend/internal/services/audit"
+	"OpenEx-Backend/internal/services/notify"
+	"OpenEx-Backend/internal/services/proposals"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ProposalRequest is the request payload for bidding on a service request
+type ProposalRequest struct {
+	Price               float64   `json:"price" binding:"min=0"`
+	EstimatedCompletion time.Time `json:"estimated_completion" binding:"required"`
+	Message             string    `json:"message" binding:"required"`
+}
+
+// errProposalUnavailable is returned when the service request of a proposal is no longer open
+var errProposalUnavailable = errors.New("proposal is no longer available")
+
+// errProposalClosed is returned when a proposal was withdrawn or declined meanwhile
+var errProposalClosed = errors.New("proposal is no longer open")
+
+// CreateProposal lets a provider bid on another user's open service request
+func CreateProposal(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	// Choosing a proposal shares contact details, so the provider needs a way to be reached
+	if user.ContactDetails == "" || strings.Contains(user.ContactDetails, "@") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Please update your phone number in contact details before sending a proposal"})
+		return
+	}
+
+	var req ProposalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !req.EstimatedCompletion.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "estimated_completion must be in the future"})
+		return
+	}
+
+	// Requests hidden after reports, or whose requester is restricted, take no proposals
+	var serviceRequest models.ServiceRequest
+	if err := database.DB.Scopes(visibleOwner("requester_id")).Preload("Requester").
+		First(&serviceRequest, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Service request not found"})
+		return
+	}
+
+	if serviceRequest.RequesterID == user.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot send a proposal for your own request"})
+		return
+	}
+
+	if serviceRequest.Status != "open" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Service request is not open"})
+		return
+	}
+
+	var existing int64
+	database.DB.Model(&models.Proposal{}).
+		Where("service_request_id = ? AND provider_id = ? AND status = ?", serviceRequest.ID, user.ID, "open").
+		Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "You already have a proposal on this request; withdraw it to send a new one"})
+		return
+	}
+
+	proposal := models.Proposal{
+		ServiceRequestID:    serviceRequest.ID,
+		ProviderID:          user.ID,
+		Price:               req.Price,
+		EstimatedCompletion: req.EstimatedCompletion,
+		Message:             strings.TrimSpace(req.Message),
+		Status:              "open",
+	}
+	if err := database.DB.Create(&proposal).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create proposal"})
+		return
+	}
+
+	go notify.ProposalReceived(serviceRequest.Requester, serviceRequest.Title, user.Name, proposal.Price, proposal.EstimatedCompletion)
+
+	c.JSON(http.StatusCreated, proposal)
+}
+
+// proposalSummary is the view of a proposal shown to the requester and the provider
+func proposalSummary(proposal models.Proposal) gin.H {
+	return gin.H{
+		"id":                  proposal.ID,
+		"serviceRequestId":    proposal.ServiceRequestID,
+		"requestTitle":        proposal.ServiceRequest.Title,
+		"providerId":          proposal.ProviderID,
+		"provider":            proposal.Provider.Name,
+		"price":               proposal.Price,
+		"estimatedCompletion": proposal.EstimatedCompletion,
+		"message":             proposal.Message,
+		"status":              proposal.Status,
+		"createdAt":           proposal.CreatedAt,
+	}
+}
+
+// ListProposals returns the proposals on a service request, cheapest first (requester only)
+func ListProposals(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var serviceRequest models.ServiceRequest
+	if err := database.DB.First(&serviceRequest, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Service request not found"})
+		return
+	}
+
+	if serviceRequest.RequesterID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the requester can see the proposals on this request"})
+		return
+	}
+
+	var proposals []models.Proposal
+	database.DB.Preload("Provider").Preload("ServiceRequest").
+		Where("service_request_id = ? AND status <> ?", serviceRequest.ID, "withdrawn").
+		Order("price, estimated_completion").
+		Find(&proposals)
+
+	results := []gin.H{}
+	for _, proposal := range proposals {
+		results = append(results, proposalSummary(proposal))
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// GetMyProposals returns the proposals sent by the authenticated user
+func GetMyProposals(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var proposals []models.Proposal
+	database.DB.Preload("Provider").Preload("ServiceRequest").
+		Where("provider_id = ?", user.ID).
+		Order("created_at DESC").
+		Find(&proposals)
+
+	results := []gin.H{}
+	for _, proposal := range proposals {
+		results = append(results, proposalSummary(proposal))
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// AcceptProposal chooses a proposal for the requester's service request. The request moves to
+// in-progress with the proposal's provider, price and estimated completion, contact details are
+// shared, and the other proposals are declined.
+func AcceptProposal(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var proposal models.Proposal
+	if err := database.DB.Preload("Provider").Preload("ServiceRequest").First(&proposal, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Proposal not found"})
+		return
+	}
+
+	if proposal.ServiceRequest.RequesterID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the requester can accept this proposal"})
+		return
+	}
+
+	if proposal.Status != "open" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only open proposals can be accepted", "status": proposal.Status})
+		return
+	}
+
+	if proposal.ServiceRequest.HiddenAt != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This request is hidden while moderators review reports on it"})
+		return
+	}
+
+	provider := proposal.Provider
+	if provider.AnonymizedAt != nil || provider.IsBanned() || provider.IsSuspended() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This provider is currently unavailable"})
+		return
+	}
+
+	serviceRequest := proposal.ServiceRequest
+	var declined []models.Proposal
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Only one proposal wins, even if the requester double-clicks, and none on a request hidden meanwhile
+		now := time.Now()
+		result := tx.Model(&models.ServiceRequest{}).Where("id = ? AND status = ? AND hidden_at IS NULL", serviceRequest.ID, "open").
+			Updates(map[string]interface{}{
+				"status":       "in-progress",
+				"provider_id":  proposal.ProviderID,
+				"proposal_id":  proposal.ID,
+				"agreed_price": proposal.Price,
+				"due_at":       proposal.EstimatedCompletion,
+				"accepted_at":  now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errProposalUnavailable
+		}
+
+		// A proposal withdrawn or declined meanwhile can't be accepted anymore
+		result = tx.Model(&models.Proposal{}).Where("id = ? AND status = ?", proposal.ID, "open").
+			Update("status", "accepted")
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errProposalClosed
+		}
+
+		if err := tx.Preload("Provider").
+			Where("service_request_id = ? AND id <> ? AND status = ?", serviceRequest.ID, proposal.ID, "open").
+			Find(&declined).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Proposal{}).
+			Where("service_request_id = ? AND id <> ? AND status = ?", serviceRequest.ID, proposal.ID, "open").
+			Update("status", "declined").Error
+	})
+	if errors.Is(err, errProposalUnavailable) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Service request is no longer open"})
+		return
+	}
+	if errors.Is(err, errProposalClosed) {
+		c.JSON(http.StatusConflict, gin.H{"error": "This proposal was withdrawn or declined meanwhile"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept proposal"})
+		return
+	}
+
+	database.DB.First(&serviceRequest, serviceRequest.ID)
+
+	audit.Record(audit.UserActor(user), audit.Entry{
+		Action:     "proposal.accept",
+		TargetType: "proposal",
+		TargetID:   proposal.ID,
+		FromStatus: "open",
+		ToStatus:   "accepted",
+		Reason:     fmt.Sprintf("Service request #%d assigned to user #%d", serviceRequest.ID, proposal.ProviderID),
+	})
+
+	go notify.ProposalAccepted(provider, serviceRequest.Title)
+	for _, other := range declined {
+		go notify.ProposalDeclined(other.Provider, serviceRequest.Title, "The requester chose another proposal.")
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":           "Proposal accepted successfully",
+		"request":           serviceRequest,
+		"requester_contact": user.ContactDetails,
+		"provider_contact":  provider.ContactDetails,
+	})
+}
+
+// DeclineProposal turns down a single proposal on the requester's service request
+func DeclineProposal(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var proposal models.Proposal
+	if err := database.DB.Preload("Provider").Preload("ServiceRequest").First(&proposal, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Proposal not found"})
+		return
+	}
+
+	if proposal.ServiceRequest.RequesterID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the requester can decline this proposal"})
+		return
+	}
+
+	if proposal.Status != "open" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only open proposals can be declined", "status": proposal.Status})
+		return
+	}
+
+	// The status condition keeps a proposal accepted or withdrawn meanwhile as it is
+	result := database.DB.Model(&models.Proposal{}).
+		Where("id = ? AND status = ?", proposal.ID, "open").
+		Update("status", "declined")
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decline proposal"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "This proposal was accepted or withdrawn meanwhile"})
+		return
+	}
+	proposal.Status = "declined"
+
+	go notify.ProposalDeclined(proposal.Provider, proposal.ServiceRequest.Title, "")
+
+	c.JSON(http.StatusOK, proposalSummary(proposal))
+}
+
+// WithdrawProposal takes back a proposal that has not been accepted (provider only)
+func WithdrawProposal(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var proposal models.Proposal
+	if err := database.DB.Preload("Provider").Preload("ServiceRequest").First(&proposal, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Proposal not found"})
+		return
+	}
+
+	if proposal.ProviderID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the provider can withdraw this proposal"})
+		return
+	}
+
+	result := database.DB.Model(&models.Proposal{}).
+		Where("id = ? AND status = ?", proposal.ID, "open").
+		Update("status", "withdrawn")
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to withdraw proposal"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This proposal can no longer be withdrawn", "status": proposal.Status})
+		return
+	}
+
+	proposal.Status = "withdrawn"
+	c.JSON(http.StatusOK, proposalSummary(proposal))
+}
+
+// declineOpenProposals declines the open proposals on a service request that is no longer
+// taking them and tells their providers why
+func declineOpenProposals(serviceRequestID uint, reason string) {
+	if err := proposals.DeclineOpen(serviceRequestID, reason); err != nil {
+		log.Printf("Error declining the open proposals on service request #%d: %v", serviceRequestID, err)
+	}
+}
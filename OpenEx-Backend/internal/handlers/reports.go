@@ -0,0 +1,466 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"OpenEx-Backend/internal/database"
+	"OpenEx-Backend/internal/models"
+	"OpenEx-Backend/internal/rbac"
+	"OpenEx-Backend/internal/services/audit"
+	"OpenEx-Backend/internal/services/notify"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// reportCategories are the reasons a user can pick when reporting something
+var reportCategories = map[string]bool{
+	"scam":       true,
+	"prohibited": true,
+	"offensive":  true,
+	"harassment": true,
+	"spam":       true,
+	"other":      true,
+}
+
+// reportTargetLabels are the human readable names of reportable records, used in emails
+var reportTargetLabels = map[string]string{
+	"item":            "item",
+	"service":         "service",
+	"requested_item":  "requested item",
+	"service_request": "service request",
+	"user":            "user",
+}
+
+// ReportRequest is the request payload for reporting a listing or user
+type ReportRequest struct {
+	Category string `json:"category" binding:"required"`
+	Details  string `json:"details"`
+}
+
+// ResolveReportRequest is the request payload for triaging the reports about a target
+type ResolveReportRequest struct {
+	Action string `json:"action" binding:"required"` // dismiss, uphold
+	Note   string `json:"note"`
+}
+
+// reportTarget is what reporting and triage need to know about a reported record
+type reportTarget struct {
+	OwnerID  uint
+	HostelID uint
+	Title    string
+	Status   string
+	HiddenAt *time.Time
+}
+
+// reportHideThreshold returns how many distinct reporters hide a target until staff review it
+func reportHideThreshold() int64 {
+	if value, err := strconv.Atoi(os.Getenv("REPORT_HIDE_THRESHOLD")); err == nil && value > 0 {
+		return int64(value)
+	}
+	return 3
+}
+
+// reportTargetModel returns an empty model of the reported record's table, for updates
+func reportTargetModel(targetType string) interface{} {
+	switch targetType {
+	case "item":
+		return &models.Item{}
+	case "service":
+		return &models.Service{}
+	case "requested_item":
+		return &models.RequestedItem{}
+	case "service_request":
+		return &models.ServiceRequest{}
+	}
+	return &models.User{}
+}
+
+// loadReportTarget loads the owner, hostel and state of a reportable record
+func loadReportTarget(targetType string, id uint) (reportTarget, error) {
+	switch targetType {
+	case "item":
+		var item models.Item
+		err := database.DB.First(&item, id).Error
+		return reportTarget{item.UserID, item.HostelID, item.Title, item.Status, item.HiddenAt}, err
+	case "service":
+		var service models.Service
+		err := database.DB.First(&service, id).Error
+		return reportTarget{service.UserID, service.HostelID, service.Title, service.Status, service.HiddenAt}, err
+	case "requested_item":
+		var requestedItem models.RequestedItem
+		err := database.DB.First(&requestedItem, id).Error
+		return reportTarget{requestedItem.BuyerID, requestedItem.HostelID, requestedItem.Title, requestedItem.Status, requestedItem.HiddenAt}, err
+	case "service_request":
+		var serviceRequest models.ServiceRequest
+		err := database.DB.First(&serviceRequest, id).Error
+		return reportTarget{serviceRequest.RequesterID, serviceRequest.HostelID, serviceRequest.Title, serviceRequest.Status, serviceRequest.HiddenAt}, err
+	case "user":
+		var user models.User
+		if err := database.DB.First(&user, id).Error; err != nil {
+			return reportTarget{}, err
+		}
+		if user.AnonymizedAt != nil {
+			return reportTarget{}, gorm.ErrRecordNotFound
+		}
+		return reportTarget{user.ID, user.HostelID, user.Name, accountStatus(user), user.HiddenAt}, nil
+	}
+	return reportTarget{}, fmt.Errorf("unknown report target %q", targetType)
+}
+
+// ReportItem reports an item listing
+func ReportItem(c *gin.Context) {
+	fileReport(c, "item")
+}
+
+// ReportService reports an offered service
+func ReportService(c *gin.Context) {
+	fileReport(c, "service")
+}
+
+// ReportRequestedItem reports a wanted post
+func ReportRequestedItem(c *gin.Context) {
+	fileReport(c, "requested_item")
+}
+
+// ReportServiceRequest reports a service request
+func ReportServiceRequest(c *gin.Context) {
+	fileReport(c, "service_request")
+}
+
+// ReportUser reports another user, e.g. for harassment
+func ReportUser(c *gin.Context) {
+	fileReport(c, "user")
+}
+
+// fileReport stores a report from the authenticated user and hides the target once enough
+// different users have reported it
+func fileReport(c *gin.Context, targetType string) {
+	user := c.MustGet("user").(models.User)
+
+	var req ReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	category := strings.ToLower(strings.TrimSpace(req.Category))
+	if !reportCategories[category] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "category must be one of scam, prohibited, offensive, harassment, spam or other"})
+		return
+	}
+
+	targetID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	target, err := loadReportTarget(targetType, uint(targetID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reported " + reportTargetLabels[targetType] + " not found"})
+		return
+	}
+
+	if target.OwnerID == user.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot report your own content"})
+		return
+	}
+
+	report := models.Report{
+		ReporterID: user.ID,
+		TargetType: targetType,
+		TargetID:   uint(targetID),
+		HostelID:   target.HostelID,
+		Category:   category,
+		Details:    strings.TrimSpace(req.Details),
+		Status:     "open",
+	}
+	// The unique index allows one report per reporter and target, so a single user can't push
+	// content over the hide threshold, not even with concurrent requests
+	err = database.DB.Create(&report).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		c.JSON(http.StatusConflict, gin.H{"error": "You have already reported this " + reportTargetLabels[targetType]})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit report"})
+		return
+	}
+
+	var reporters int64
+	database.DB.Model(&models.Report{}).
+		Where("target_type = ? AND target_id = ? AND status = ?", targetType, targetID, "open").
+		Distinct("reporter_id").
+		Count(&reporters)
+
+	if reporters >= reportHideThreshold() && target.HiddenAt == nil {
+		result := database.DB.Model(reportTargetModel(targetType)).
+			Where("id = ? AND hidden_at IS NULL", targetID).
+			Update("hidden_at", time.Now())
+		if result.Error == nil && result.RowsAffected > 0 {
+			audit.Record(audit.WorkerActor("reports"), audit.Entry{
+				Action:     targetType + ".hide",
+				TargetType: targetType,
+				TargetID:   uint(targetID),
+				FromStatus: target.Status,
+				ToStatus:   target.Status,
+				Reason:     fmt.Sprintf("Reported by %d users", reporters),
+			})
+			if targetType == "service_request" {
+				declineOpenProposals(uint(targetID), "The request was taken down after reports.")
+			}
+		}
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Thanks, a moderator will review your report",
+		"id":      report.ID,
+	})
+}
+
+// reportSummary is the view of a report shown to staff
+func reportSummary(report models.Report) gin.H {
+	return gin.H{
+		"id":           report.ID,
+		"reporterId":   report.ReporterID,
+		"reporter":     report.Reporter.Name,
+		"targetType":   report.TargetType,
+		"targetId":     report.TargetID,
+		"hostelId":     report.HostelID,
+		"category":     report.Category,
+		"details":      report.Details,
+		"status":       report.Status,
+		"resolvedById": report.ResolvedByID,
+		"resolution":   report.Resolution,
+		"resolvedAt":   report.ResolvedAt,
+		"createdAt":    report.CreatedAt,
+	}
+}
+
+// ListReports returns individual reports, newest first (staff only, limited to the moderator's hostel)
+func ListReports(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	query := database.DB.Preload("Reporter")
+	if hostelID := rbac.HostelScope(user); hostelID != nil {
+		query = query.Where("hostel_id = ?", *hostelID)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if targetType := c.Query("target_type"); targetType != "" {
+		query = query.Where("target_type = ?", targetType)
+	}
+	if targetID := c.Query("target_id"); targetID != "" {
+		query = query.Where("target_id = ?", targetID)
+	}
+	if category := c.Query("category"); category != "" {
+		query = query.Where("category = ?", category)
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	var reports []models.Report
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&reports).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reports"})
+		return
+	}
+
+	results := []gin.H{}
+	for _, report := range reports {
+		results = append(results, reportSummary(report))
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// GetReportQueue groups open reports by what was reported, most reported first
+// (staff only, limited to the moderator's hostel)
+func GetReportQueue(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	query := database.DB.Where("status = ?", "open")
+	if hostelID := rbac.HostelScope(user); hostelID != nil {
+		query = query.Where("hostel_id = ?", *hostelID)
+	}
+
+	var reports []models.Report
+	if err := query.Order("created_at, id").Find(&reports).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reports"})
+		return
+	}
+
+	type queueEntry struct {
+		TargetType      string         `json:"targetType"`
+		TargetID        uint           `json:"targetId"`
+		Title           string         `json:"title"`
+		Status          string         `json:"status"`
+		Hidden          bool           `json:"hidden"`
+		ReportID        uint           `json:"reportId"` // Oldest open report, pass it to the resolve endpoint
+		Reporters       int            `json:"reporters"`
+		Categories      map[string]int `json:"categories"`
+		FirstReportedAt time.Time      `json:"firstReportedAt"`
+		reporterIDs     map[uint]bool
+	}
+
+	entries := map[string]*queueEntry{}
+	var order []*queueEntry
+	for _, report := range reports {
+		key := fmt.Sprintf("%s:%d", report.TargetType, report.TargetID)
+		entry := entries[key]
+		if entry == nil {
+			entry = &queueEntry{
+				TargetType:      report.TargetType,
+				TargetID:        report.TargetID,
+				ReportID:        report.ID,
+				Categories:      map[string]int{},
+				FirstReportedAt: report.CreatedAt,
+				reporterIDs:     map[uint]bool{},
+			}
+			if target, err := loadReportTarget(report.TargetType, report.TargetID); err == nil {
+				entry.Title = target.Title
+				entry.Status = target.Status
+				entry.Hidden = target.HiddenAt != nil
+			}
+			entries[key] = entry
+			order = append(order, entry)
+		}
+		entry.Categories[report.Category]++
+		entry.reporterIDs[report.ReporterID] = true
+		entry.Reporters = len(entry.reporterIDs)
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return order[i].Reporters > order[j].Reporters
+	})
+
+	c.JSON(http.StatusOK, order)
+}
+
+// ResolveReport dismisses or upholds every open report about the same target as the given
+// report. Dismissing makes hidden content visible again; upholding removes a listing and
+// notifies its owner, or keeps a reported user's listings hidden until they are reinstated.
+func ResolveReport(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var req ResolveReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Action != "dismiss" && req.Action != "uphold" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "action must be dismiss or uphold"})
+		return
+	}
+
+	var report models.Report
+	if err := database.DB.First(&report, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Report not found"})
+		return
+	}
+
+	if !rbac.CanAccessHostel(user, report.HostelID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only resolve reports in your hostel"})
+		return
+	}
+
+	if report.Status != "open" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Report has already been resolved"})
+		return
+	}
+
+	target, err := loadReportTarget(report.TargetType, report.TargetID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load reported content"})
+		return
+	}
+	targetExists := err == nil
+
+	status := "dismissed"
+	if req.Action == "uphold" {
+		status = "upheld"
+	}
+	note := strings.TrimSpace(req.Note)
+
+	newStatus := target.Status
+	var resolved int64
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&models.Report{}).
+			Where("target_type = ? AND target_id = ? AND status = ?", report.TargetType, report.TargetID, "open").
+			Updates(map[string]interface{}{
+				"status":         status,
+				"resolved_by_id": user.ID,
+				"resolution":     note,
+				"resolved_at":    &now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		resolved = result.RowsAffected
+
+		if !targetExists {
+			return nil
+		}
+
+		record := tx.Model(reportTargetModel(report.TargetType)).Where("id = ?", report.TargetID)
+		switch {
+		case req.Action == "dismiss":
+			return record.Update("hidden_at", nil).Error
+		case report.TargetType == "user":
+			return tx.Model(&models.User{}).Where("id = ? AND hidden_at IS NULL", report.TargetID).
+				Update("hidden_at", &now).Error
+		default:
+			newStatus = "removed"
+			return record.Updates(map[string]interface{}{"status": newStatus, "hidden_at": nil}).Error
+		}
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve reports"})
+		return
+	}
+
+	audit.Record(audit.UserActor(user), audit.Entry{
+		Action:     "report." + req.Action,
+		TargetType: report.TargetType,
+		TargetID:   report.TargetID,
+		FromStatus: target.Status,
+		ToStatus:   newStatus,
+		Reason:     note,
+	})
+
+	if targetExists && req.Action == "uphold" && report.TargetType == "service_request" {
+		declineOpenProposals(report.TargetID, "A moderator removed the request.")
+	}
+
+	if targetExists && req.Action == "uphold" && report.TargetType != "user" {
+		var owner models.User
+		if err := database.DB.First(&owner, target.OwnerID).Error; err == nil {
+			reason := note
+			if reason == "" {
+				reason = "It breaks the OpenEx community guidelines"
+			}
+			go notify.ListingRemoved(owner, reportTargetLabels[report.TargetType], target.Title, reason)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  fmt.Sprintf("%d report(s) %s", resolved, status),
+		"resolved": resolved,
+		"status":   status,
+	})
+}
@@ -53,6 +53,11 @@ func CreateRequest(c *gin.Context) {
 		return
 	}
 
+	if item.User.IsBanned() || item.User.IsSuspended() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This item is currently unavailable"})
+		return
+	}
+
 	if item.Status != "approved" || item.UserID == user.ID {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot request your own item"})
 		return
@@ -327,6 +332,8 @@ func ApproveRequest(c *gin.Context) {
 			},
 		})
 	} else {
+		releaseDeclinedTransaction(request)
+
 		// For rejected requests, just return the updated request
 		c.JSON(http.StatusOK, gin.H{
 			"request": request,
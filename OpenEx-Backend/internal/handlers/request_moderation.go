@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"net/http"
+
+	"OpenEx-Backend/internal/database"
+	"OpenEx-Backend/internal/models"
+	"OpenEx-Backend/internal/rbac"
+	"OpenEx-Backend/internal/services/moderator"
+	"OpenEx-Backend/internal/services/notify"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// requestReviewOrder puts the highest priority requests first and otherwise the oldest
+func requestReviewOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("review_priority DESC").Order("created_at ASC")
+}
+
+// moderateRequestedItem runs a new requested item through the requested item pipeline. Clean
+// requests open right away, borderline ones wait for a moderator and offending ones are rejected.
+func moderateRequestedItem(requestedItem *models.RequestedItem, buyer models.User) {
+	moderateSubmission(requestedItemSubmission(requestedItem, buyer), moderator.Content{
+		Title:       requestedItem.Title,
+		Description: requestedItem.Description,
+	})
+}
+
+// requestedItemSubmission describes a requested item for the shared moderation helpers
+func requestedItemSubmission(requestedItem *models.RequestedItem, buyer models.User) submission {
+	return submission{
+		Row:      requestedItem,
+		Kind:     moderator.KindRequestedItem,
+		Label:    "Requested item",
+		ID:       requestedItem.ID,
+		HostelID: requestedItem.HostelID,
+		Status:   requestedItem.Status,
+		Notify: func(reason string) {
+			notify.RequestRejected(buyer, "requested item", requestedItem.Title, reason)
+		},
+	}
+}
+
+// moderateServiceRequest runs a new service request through the service request pipeline. See
+// moderateRequestedItem.
+func moderateServiceRequest(serviceRequest *models.ServiceRequest, requester models.User) {
+	moderateSubmission(serviceRequestSubmission(serviceRequest, requester), moderator.Content{
+		Title:       serviceRequest.Title,
+		Description: serviceRequest.Description,
+		Category:    serviceRequest.Category,
+	})
+}
+
+// serviceRequestSubmission describes a service request for the shared moderation helpers
+func serviceRequestSubmission(serviceRequest *models.ServiceRequest, requester models.User) submission {
+	return submission{
+		Row:      serviceRequest,
+		Kind:     moderator.KindServiceRequest,
+		Label:    "Service request",
+		ID:       serviceRequest.ID,
+		HostelID: serviceRequest.HostelID,
+		Status:   serviceRequest.Status,
+		Notify: func(reason string) {
+			notify.RequestRejected(requester, "service request", serviceRequest.Title, reason)
+		},
+	}
+}
+
+// ListRequestedItemsForReview returns the requested items waiting for moderation, highest
+// priority first (staff only, limited to the moderator's hostel)
+func ListRequestedItemsForReview(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	statuses, ok := reviewQueueStatuses(c.Query("queue"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "queue must be pending, review or all"})
+		return
+	}
+
+	query := database.DB.Preload("Buyer").Where("status IN ?", statuses).Scopes(requestReviewOrder)
+	if hostelID := rbac.HostelScope(user); hostelID != nil {
+		query = query.Where("hostel_id = ?", *hostelID)
+	}
+
+	var requestedItems []models.RequestedItem
+	query.Find(&requestedItems)
+
+	results := []gin.H{}
+	for _, item := range requestedItems {
+		results = append(results, gin.H{
+			"id":             item.ID,
+			"title":          item.Title,
+			"description":    item.Description,
+			"maxPrice":       item.MaxPrice,
+			"quantity":       item.Quantity,
+			"status":         item.Status,
+			"reviewReason":   item.ReviewReason,
+			"reviewPriority": item.ReviewPriority,
+			"buyerId":        item.BuyerID,
+			"buyer":          item.Buyer.Name,
+			"hostelId":       item.HostelID,
+			"createdAt":      item.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// ApproveRequestedItem opens a held back requested item (moderators only)
+func ApproveRequestedItem(c *gin.Context) {
+	decideRequestedItem(c, true)
+}
+
+// RejectRequestedItem rejects a held back requested item with a reason shown to the buyer (moderators only)
+func RejectRequestedItem(c *gin.Context) {
+	decideRequestedItem(c, false)
+}
+
+// decideRequestedItem records a moderator's decision on a requested item waiting for review
+func decideRequestedItem(c *gin.Context, approved bool) {
+	var requestedItem models.RequestedItem
+	if err := database.DB.Preload("Buyer").First(&requestedItem, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Requested item not found"})
+		return
+	}
+
+	if !decideSubmission(c, requestedItemSubmission(&requestedItem, requestedItem.Buyer), approved) {
+		return
+	}
+	c.JSON(http.StatusOK, requestedItem)
+}
+
+// ListServiceRequestsForReview returns the service requests waiting for moderation, highest
+// priority first (staff only, limited to the moderator's hostel)
+func ListServiceRequestsForReview(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	statuses, ok := reviewQueueStatuses(c.Query("queue"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "queue must be pending, review or all"})
+		return
+	}
+
+	query := database.DB.Preload("Requester").Where("status IN ?", statuses).Scopes(requestReviewOrder)
+	if hostelID := rbac.HostelScope(user); hostelID != nil {
+		query = query.Where("hostel_id = ?", *hostelID)
+	}
+
+	var serviceRequests []models.ServiceRequest
+	query.Find(&serviceRequests)
+
+	results := []gin.H{}
+	for _, request := range serviceRequests {
+		results = append(results, gin.H{
+			"id":             request.ID,
+			"title":          request.Title,
+			"description":    request.Description,
+			"budget":         request.Budget,
+			"category":       request.Category,
+			"status":         request.Status,
+			"reviewReason":   request.ReviewReason,
+			"reviewPriority": request.ReviewPriority,
+			"requesterId":    request.RequesterID,
+			"requester":      request.Requester.Name,
+			"hostelId":       request.HostelID,
+			"createdAt":      request.CreatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// ApproveServiceRequest opens a held back service request (moderators only)
+func ApproveServiceRequest(c *gin.Context) {
+	decideServiceRequest(c, true)
+}
+
+// RejectServiceRequest rejects a held back service request with a reason shown to the requester (moderators only)
+func RejectServiceRequest(c *gin.Context) {
+	decideServiceRequest(c, false)
+}
+
+// decideServiceRequest records a moderator's decision on a service request waiting for review
+func decideServiceRequest(c *gin.Context, approved bool) {
+	var serviceRequest models.ServiceRequest
+	if err := database.DB.Preload("Requester").First(&serviceRequest, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Service request not found"})
+		return
+	}
+
+	if !decideSubmission(c, serviceRequestSubmission(&serviceRequest, serviceRequest.Requester), approved) {
+		return
+	}
+	if !approved {
+		declineOpenProposals(serviceRequest.ID, "A moderator rejected the request.")
+	}
+	c.JSON(http.StatusOK, serviceRequest)
+}
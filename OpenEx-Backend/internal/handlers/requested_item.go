@@ -3,12 +3,14 @@ package handlers
 import (
 	"fmt"
 	"log"
+	"math"
 	"net/http"
-	"time"
 
+	"OpenEx-Backend/internal/config"
 	"OpenEx-Backend/internal/database"
 	"OpenEx-Backend/internal/models"
 	"OpenEx-Backend/internal/services/email"
+	"OpenEx-Backend/internal/services/matcher"
 
 	"github.com/gin-gonic/gin"
 )
@@ -18,9 +20,10 @@ type RequestedItemRequest struct {
 	Title       string  `json:"title" binding:"required"`
 	Description string  `json:"description" binding:"required"`
 	MaxPrice    float64 `json:"max_price"`
+	Quantity    int     `json:"quantity" binding:"omitempty,min=1"`
 }
 
-// CreateRequestedItem creates a new requested item
+// CreateRequestedItem creates a new requested item. It is moderated before other users see it.
 func CreateRequestedItem(c *gin.Context) {
 	user := c.MustGet("user").(models.User)
 
@@ -30,17 +33,80 @@ func CreateRequestedItem(c *gin.Context) {
 		return
 	}
 
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+
 	requestedItem := models.RequestedItem{
 		BuyerID:     user.ID,
 		HostelID:    user.HostelID,
 		Title:       req.Title,
 		Description: req.Description,
 		MaxPrice:    req.MaxPrice,
-		Status:      "open",
+		Quantity:    req.Quantity,
+		Status:      "pending",
+	}
+
+	if err := database.DB.Create(&requestedItem).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create requested item"})
+		return
+	}
+
+	moderateRequestedItem(&requestedItem, user)
+
+	// Point the buyer at listings that already fit, next to the usual fields
+	suggestions := []gin.H{}
+	if requestedItem.Status != "rejected" {
+		suggestions = suggestListings(requestedItem)
+	}
+	c.JSON(http.StatusCreated, struct {
+		models.RequestedItem
+		Suggestions []gin.H `json:"suggestions"`
+	}{requestedItem, suggestions})
+}
+
+// GetRequestedItemSuggestions returns the approved listings that best fit a requested item (buyer only)
+func GetRequestedItemSuggestions(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var requestedItem models.RequestedItem
+	if err := database.DB.First(&requestedItem, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Requested item not found"})
+		return
+	}
+
+	if requestedItem.BuyerID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
+		return
 	}
 
-	database.DB.Create(&requestedItem)
-	c.JSON(http.StatusCreated, requestedItem)
+	c.JSON(http.StatusOK, suggestListings(requestedItem))
+}
+
+// suggestListings ranks the approved items in the buyer's and nearby hostels against a
+// requested item and returns the best few
+func suggestListings(requestedItem models.RequestedItem) []gin.H {
+	var items []models.Item
+	query := database.DB.Preload("Hostel").Scopes(visibleOwner("user_id")).
+		Where("status = ? AND hostel_id IN ?", "approved", matcher.NearbyHostelIDs(requestedItem.HostelID))
+	if requestedItem.MaxPrice > 0 {
+		query = query.Where("price <= ?", requestedItem.MaxPrice)
+	}
+	query.Order("created_at DESC").Limit(500).Find(&items)
+
+	suggestions := []gin.H{}
+	for _, match := range matcher.Rank(requestedItem, items, 5) {
+		suggestions = append(suggestions, gin.H{
+			"id":       match.Item.ID,
+			"title":    match.Item.Title,
+			"price":    match.Item.Price,
+			"image":    match.Item.Image,
+			"hostelId": match.Item.HostelID,
+			"hostel":   match.Item.Hostel.Name,
+			"score":    math.Round(match.Score*100) / 100,
+		})
+	}
+	return suggestions
 }
 
 // ListRequestedItems returns all open requested items
@@ -48,6 +114,7 @@ func ListRequestedItems(c *gin.Context) {
 	var requestedItems []models.RequestedItem
 
 	database.DB.Where("status = ?", "open").
+		Scopes(visibleOwner("buyer_id")).
 		Preload("Buyer").
 		Preload("Hostel").
 		Find(&requestedItems)
@@ -60,6 +127,8 @@ func ListRequestedItems(c *gin.Context) {
 			"title":       item.Title,
 			"description": item.Description,
 			"maxPrice":    item.MaxPrice,
+			"quantity":    item.Quantity,
+			"remaining":   item.RemainingQuantity(),
 			"status":      item.Status,
 			"createdAt":   item.CreatedAt,
 			"buyer":       item.Buyer.Name,
@@ -72,74 +141,17 @@ func ListRequestedItems(c *gin.Context) {
 	c.JSON(http.StatusOK, enrichedItems)
 }
 
-// FulfillRequest is the request payload for fulfilling a requested item
-type FulfillRequest struct {
-	RequestedItemID uint    `json:"requested_item_id" binding:"required"`
-	Price           float64 `json:"price" binding:"required"`
-	Image           string  `json:"image"`
-	Quantity        int     `json:"quantity"`
-}
-
-// FulfillRequestedItem allows a seller to fulfill a requested item
-func FulfillRequestedItem(c *gin.Context) {
-	user := c.MustGet("user").(models.User)
-
-	var req FulfillRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Get the requested item
-	var requestedItem models.RequestedItem
-	if err := database.DB.First(&requestedItem, req.RequestedItemID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Requested item not found"})
-		return
-	}
-
-	// Validate quantity
-	if req.Quantity <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Quantity must be greater than 0"})
-		return
-	}
-
-	// Create a new item
-	item := models.Item{
-		UserID:      user.ID,
-		HostelID:    user.HostelID,
-		Title:       requestedItem.Title,
-		Description: requestedItem.Description,
-		Price:       req.Price,
-		Quantity:    req.Quantity,
-		Status:      "approved",
-	}
-
-	if err := database.DB.Create(&item).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create item"})
-		return
-	}
-
-	// Update the requested item status
-	requestedItem.Status = "fulfilled"
-	database.DB.Save(&requestedItem)
-
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Request fulfilled successfully",
-		"item":    item,
-	})
-}
-
-// Helper function to send notification email to buyer when their request is fulfilled
-func sendRequestFulfilledEmail(buyer, seller models.User, item models.Item, request models.TransactionRequest, requestedItem models.RequestedItem) {
+// Helper function to send notification email to buyer when a seller's offer on their request is visible
+func sendOfferReceivedEmail(buyer, seller models.User, offer models.Offer, requestedItem models.RequestedItem) {
 	// Email subject
-	subject := fmt.Sprintf("Your Request Has Been Fulfilled: %s", requestedItem.Title)
+	subject := fmt.Sprintf("New Offer for Your Request: %s", requestedItem.Title)
 
 	// Create HTML content for email
 	htmlContent := fmt.Sprintf(`
 <html>
 <body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto;">
     <div style="background-color: #f7f7f7; padding: 20px; border-radius: 5px; margin-bottom: 20px;">
-        <h1 style="color: #4a6ee0; margin: 0;">Your Request Has Been Fulfilled!</h1>
+        <h1 style="color: #4a6ee0; margin: 0;">You Have a New Offer!</h1>
         <p style="margin-top: 5px; color: #777;">Someone is offering exactly what you're looking for</p>
     </div>
     
@@ -164,15 +176,15 @@ func sendRequestFulfilledEmail(buyer, seller models.User, item models.Item, requ
                 <td style="padding: 10px; border-bottom: 1px solid #eee;">%s</td>
             </tr>
             <tr>
-                <td style="padding: 10px; border-bottom: 1px solid #eee;"><strong>Fulfilled Date:</strong></td>
+                <td style="padding: 10px; border-bottom: 1px solid #eee;"><strong>Offered On:</strong></td>
                 <td style="padding: 10px; border-bottom: 1px solid #eee;">%s</td>
             </tr>
         </table>
         
-        <p>Please log in to your OpenEx account to view the offer and approve the transaction if you'd like to proceed. Once approved, you'll be able to see the seller's contact information.</p>
+        <p>Please log in to your OpenEx account to compare the offers on your request and accept the one you like. Once the seller confirms, you'll be able to see their contact information.</p>
         
         <div style="text-align: center; margin: 30px 0;">
-            <a href="http://localhost:5173/app/orders/history?from=email" style="background-color: #4a6ee0; color: white; padding: 12px 20px; text-decoration: none; border-radius: 4px; font-weight: bold;">View Order History</a>
+            <a href="%s/app/buyRequests?from=email" style="background-color: #4a6ee0; color: white; padding: 12px 20px; text-decoration: none; border-radius: 4px; font-weight: bold;">View Offers</a>
         </div>
     </div>
     
@@ -181,13 +193,13 @@ func sendRequestFulfilledEmail(buyer, seller models.User, item models.Item, requ
     </div>
 </body>
 </html>
-`, requestedItem.Title, item.Price, item.Quantity, seller.Name, time.Now().Format("January 2, 2006 at 3:04 PM"))
+`, requestedItem.Title, offer.Price, offer.Quantity, seller.Name, offer.CreatedAt.Format("January 2, 2006 at 3:04 PM"), config.FrontendURL())
 
 	// Send the email
 	if err := email.SendEmail(buyer.Email, subject, htmlContent); err != nil {
-		log.Printf("Error sending fulfillment notification email to buyer: %v", err)
+		log.Printf("Error sending offer notification email to buyer: %v", err)
 	} else {
-		log.Printf("Fulfillment notification email sent to buyer: %s", buyer.Email)
+		log.Printf("Offer notification email sent to buyer: %s", buyer.Email)
 	}
 }
 
@@ -219,8 +231,20 @@ func CloseRequestedItem(c *gin.Context) {
 		return
 	}
 
-	requestedItem.Status = "closed"
-	database.DB.Save(&requestedItem)
+	// The status condition keeps a request fulfilled or expired meanwhile as it is
+	result := database.DB.Model(&models.RequestedItem{}).
+		Where("id = ? AND status = ?", requestedItem.ID, "open").
+		Update("status", "closed")
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to close request"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Request was closed or fulfilled meanwhile"})
+		return
+	}
+
+	declineActiveOffers(requestedItem.ID, "The buyer closed the request.")
 
 	c.JSON(http.StatusOK, gin.H{"message": "Request closed successfully"})
 }
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"OpenEx-Backend/internal/database"
+	"OpenEx-Backend/internal/models"
+	"OpenEx-Backend/internal/services/audit"
+	"OpenEx-Backend/internal/worker"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultRejectionReason is used when a moderator rejects a listing without giving a reason
+const defaultRejectionReason = "Your listing does not meet our community guidelines."
+
+// RejectionRequest is the request payload for rejecting an item or service
+type RejectionRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ResubmitItem lets the owner edit a rejected item and put it back into moderation
+func ResubmitItem(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var req ItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var item models.Item
+	if err := database.DB.First(&item, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+		return
+	}
+
+	if item.UserID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the owner can resubmit this item"})
+		return
+	}
+
+	if item.Status != "rejected" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only rejected items can be resubmitted"})
+		return
+	}
+
+	// Validate hostel exists
+	var hostel models.Hostel
+	if result := database.DB.First(&hostel, req.HostelID); result.Error != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid hostel ID"})
+		return
+	}
+
+	now := time.Now()
+	item.Title = req.Title
+	item.Description = req.Description
+	item.Price = req.Price
+	imageChanged := req.Image != item.Image
+	if imageChanged {
+		item.ImageHash = ""
+	}
+	item.Image = req.Image
+	item.Type = req.Type
+	item.Quantity = req.Quantity
+	item.HostelID = req.HostelID
+	item.Status = "pending"
+	item.RejectionReason = ""
+	item.ReviewReason = ""
+	item.ReviewPriority = 0
+	item.SubmittedAt = &now
+
+	if err := database.DB.Save(&item).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resubmit item"})
+		return
+	}
+
+	audit.Record(audit.UserActor(user), audit.Entry{
+		Action:     "item.resubmit",
+		TargetType: "item",
+		TargetID:   item.ID,
+		FromStatus: "rejected",
+		ToStatus:   item.Status,
+	})
+
+	worker.ItemSubmitted(&item)
+	if imageChanged && item.Image != "" {
+		go checkItemImage(item)
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+// ResubmitService lets the owner edit a rejected service and put it back into moderation
+func ResubmitService(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var req ServiceOfferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var service models.Service
+	if err := database.DB.First(&service, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
+		return
+	}
+
+	if service.UserID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the owner can resubmit this service"})
+		return
+	}
+
+	if service.Status != "rejected" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only rejected services can be resubmitted"})
+		return
+	}
+
+	now := time.Now()
+	service.Title = req.Title
+	service.Description = req.Description
+	service.Price = req.Price
+	service.Category = req.Category
+	service.Status = "pending"
+	service.RejectionReason = ""
+	service.ReviewReason = ""
+	service.ReviewPriority = 0
+	service.SubmittedAt = &now
+
+	if err := database.DB.Save(&service).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resubmit service"})
+		return
+	}
+
+	audit.Record(audit.UserActor(user), audit.Entry{
+		Action:     "service.resubmit",
+		TargetType: "service",
+		TargetID:   service.ID,
+		FromStatus: "rejected",
+		ToStatus:   service.Status,
+	})
+
+	worker.ServiceSubmitted(&service)
+
+	c.JSON(http.StatusOK, service)
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"gorm.io/gorm"
+)
+
+// reviewQueueStatuses maps the ?queue= parameter of the moderation lists to listing statuses.
+// "pending" is content automatic moderation has not looked at yet, "review" is content it
+// handed to a human, and "all" (the default) is both.
+func reviewQueueStatuses(queue string) ([]string, bool) {
+	switch queue {
+	case "", "all":
+		return []string{"pending", "needs_review"}, true
+	case "pending":
+		return []string{"pending"}, true
+	case "review":
+		return []string{"needs_review"}, true
+	}
+	return nil, false
+}
+
+// reviewQueueOrder puts the highest priority content first and otherwise the oldest submission
+func reviewQueueOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("review_priority DESC").Order("COALESCE(submitted_at, created_at) ASC")
+}
+
+// moderatableStatuses are the statuses a moderator can approve or reject a listing from.
+// Anything else was decided, expired or withdrawn meanwhile.
+var moderatableStatuses = []string{"pending", "needs_review"}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"OpenEx-Backend/internal/database"
+	"OpenEx-Backend/internal/models"
+	"OpenEx-Backend/internal/rbac"
+	"OpenEx-Backend/internal/services/audit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AssignRoleRequest is the request payload for changing a user's role
+type AssignRoleRequest struct {
+	Role     string `json:"role" binding:"required"`
+	HostelID *uint  `json:"hostel_id"` // Required for hostel_moderator
+}
+
+// ListRoles returns every role and the permissions it grants
+func ListRoles(c *gin.Context) {
+	c.JSON(http.StatusOK, rbac.Roles())
+}
+
+// AssignRole changes the role of a user (super-admin only)
+func AssignRole(c *gin.Context) {
+	admin := c.MustGet("user").(models.User)
+
+	var req AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !rbac.IsValidRole(req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown role"})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, c.Param("id")).Error; err != nil || user.AnonymizedAt != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	// Prevent admins from locking themselves out
+	if user.ID == admin.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot change your own role"})
+		return
+	}
+
+	previousRole := user.Role
+	user.Role = req.Role
+	user.ModeratedHostelID = nil
+	if req.Role == rbac.RoleHostelModerator {
+		if req.HostelID == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "hostel_id is required for hostel moderators"})
+			return
+		}
+
+		var hostel models.Hostel
+		if err := database.DB.First(&hostel, *req.HostelID).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Hostel not found"})
+			return
+		}
+		user.ModeratedHostelID = &hostel.ID
+	}
+
+	if err := database.DB.Save(&user).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update role"})
+		return
+	}
+
+	reason := ""
+	if user.ModeratedHostelID != nil {
+		reason = fmt.Sprintf("hostel #%d", *user.ModeratedHostelID)
+	}
+	audit.Record(audit.UserActor(admin), audit.Entry{
+		Action:     "user.role_assign",
+		TargetType: "user",
+		TargetID:   user.ID,
+		FromStatus: previousRole,
+		ToStatus:   user.Role,
+		Reason:     reason,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":                  user.ID,
+		"name":                user.Name,
+		"email":               user.Email,
+		"role":                user.Role,
+		"moderated_hostel_id": user.ModeratedHostelID,
+	})
+}
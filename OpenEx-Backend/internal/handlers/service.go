@@ -1,11 +1,20 @@
 package handlers
 
 import (
+	"errors"
+	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"OpenEx-Backend/internal/database"
 	"OpenEx-Backend/internal/models"
+	"OpenEx-Backend/internal/rbac"
+	"OpenEx-Backend/internal/services/audit"
+	"OpenEx-Backend/internal/services/duplicates"
+	"OpenEx-Backend/internal/services/moderationlog"
+	"OpenEx-Backend/internal/services/notify"
+	"OpenEx-Backend/internal/worker"
 
 	"github.com/gin-gonic/gin"
 )
@@ -28,6 +37,7 @@ func CreateService(c *gin.Context) {
 		return
 	}
 
+	now := time.Now()
 	service := models.Service{
 		UserID:      user.ID,
 		HostelID:    user.HostelID,
@@ -36,9 +46,47 @@ func CreateService(c *gin.Context) {
 		Price:       req.Price,
 		Category:    req.Category,
 		Status:      "pending", // Requires admin approval
+		SubmittedAt: &now,
+	}
+
+	// Re-posting the same service is handled according to DUPLICATE_ACTION
+	match := findDuplicateService(&service)
+	if match != nil {
+		switch duplicates.Action() {
+		case duplicates.ActionBlock:
+			c.JSON(http.StatusConflict, gin.H{"error": "This looks like a duplicate of an existing service", "duplicate_of": match.ID})
+			return
+		case duplicates.ActionMerge:
+			if match.UserID == user.ID {
+				var existing models.Service
+				if err := database.DB.First(&existing, match.ID).Error; err == nil {
+					c.JSON(http.StatusOK, gin.H{
+						"message": "You already offer this service",
+						"merged":  true,
+						"service": existing,
+					})
+					return
+				}
+			}
+			fallthrough // Someone else's service can't be merged into, so let a moderator decide
+		default:
+			service.Status = "needs_review"
+			service.ReviewReason = match.Reason("service")
+			service.ReviewPriority = duplicateReviewPriority
+		}
+	}
+
+	if err := database.DB.Create(&service).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create service"})
+		return
+	}
+
+	if service.Status == "needs_review" {
+		recordDuplicateFlag("service", service.ID, "pending", service.ReviewReason)
+	} else {
+		worker.ServiceSubmitted(&service)
 	}
 
-	database.DB.Create(&service)
 	c.JSON(http.StatusCreated, service)
 }
 
@@ -48,6 +96,7 @@ func ListServices(c *gin.Context) {
 
 	// Get all approved services
 	database.DB.Where("status = ?", "approved").
+		Scopes(visibleOwner("user_id")).
 		Preload("User").
 		Preload("Hostel").
 		Find(&services)
@@ -81,31 +130,142 @@ func GetMyServices(c *gin.Context) {
 	c.JSON(http.StatusOK, services)
 }
 
-// ListPendingServices returns all pending services (admin only)
+// ListPendingServices returns services waiting for moderation, highest review priority first
+// (staff only, limited to the moderator's hostel)
 func ListPendingServices(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	statuses, ok := reviewQueueStatuses(c.Query("queue"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "queue must be pending, review or all"})
+		return
+	}
+
+	query := database.DB.Where("status IN ?", statuses).Scopes(reviewQueueOrder)
+	if hostelID := rbac.HostelScope(user); hostelID != nil {
+		query = query.Where("hostel_id = ?", *hostelID)
+	}
+
 	var services []models.Service
-	database.DB.Where("status = ?", "pending").
-		Preload("User").
+	query.Preload("User").
 		Preload("Hostel").
 		Find(&services)
 	c.JSON(http.StatusOK, services)
 }
 
-// ApproveService approves a pending service (admin only)
+// ApproveService approves a pending service (moderators only)
 func ApproveService(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
 	var service models.Service
-	database.DB.First(&service, c.Param("id"))
+	if err := database.DB.First(&service, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
+		return
+	}
+
+	if !rbac.CanAccessHostel(user, service.HostelID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only moderate services in your hostel"})
+		return
+	}
+
+	// The status condition keeps a listing decided, expired or withdrawn meanwhile untouched
+	previousStatus := service.Status
+	result := database.DB.Model(&models.Service{}).
+		Where("id = ? AND status IN ?", service.ID, moderatableStatuses).
+		Updates(map[string]interface{}{
+			"status":           "approved",
+			"rejection_reason": "",
+			"review_reason":    "",
+			"review_priority":  0,
+		})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to approve service"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only pending or needs_review services can be approved", "status": previousStatus})
+		return
+	}
 	service.Status = "approved"
-	database.DB.Save(&service)
+	service.RejectionReason = ""
+	service.ReviewReason = ""
+	service.ReviewPriority = 0
+
+	audit.Record(audit.UserActor(user), audit.Entry{
+		Action:     "service.approve",
+		TargetType: "service",
+		TargetID:   service.ID,
+		FromStatus: previousStatus,
+		ToStatus:   service.Status,
+	})
+	moderationlog.RecordHuman("service", service.ID, user, "approved", "")
+
 	c.JSON(http.StatusOK, service)
 }
 
-// RejectService rejects a pending service (admin only)
+// RejectService rejects a pending service with a reason shown to the owner (moderators only)
 func RejectService(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	// The reason is optional; an empty body falls back to the default reason
+	var req RejectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		req.Reason = defaultRejectionReason
+	}
+
 	var service models.Service
-	database.DB.First(&service, c.Param("id"))
+	if err := database.DB.First(&service, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
+		return
+	}
+
+	if !rbac.CanAccessHostel(user, service.HostelID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only moderate services in your hostel"})
+		return
+	}
+
+	// The status condition keeps a listing decided, expired or withdrawn meanwhile untouched
+	previousStatus := service.Status
+	result := database.DB.Model(&models.Service{}).
+		Where("id = ? AND status IN ?", service.ID, moderatableStatuses).
+		Updates(map[string]interface{}{
+			"status":           "rejected",
+			"rejection_reason": strings.TrimSpace(req.Reason),
+			"review_reason":    "",
+			"review_priority":  0,
+		})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reject service"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only pending or needs_review services can be rejected", "status": previousStatus})
+		return
+	}
 	service.Status = "rejected"
-	database.DB.Save(&service)
+	service.RejectionReason = strings.TrimSpace(req.Reason)
+	service.ReviewReason = ""
+	service.ReviewPriority = 0
+
+	audit.Record(audit.UserActor(user), audit.Entry{
+		Action:     "service.reject",
+		TargetType: "service",
+		TargetID:   service.ID,
+		FromStatus: previousStatus,
+		ToStatus:   service.Status,
+		Reason:     service.RejectionReason,
+	})
+	moderationlog.RecordHuman("service", service.ID, user, "rejected", service.RejectionReason)
+
+	var owner models.User
+	if err := database.DB.First(&owner, service.UserID).Error; err == nil {
+		go notify.ListingRejected(owner, "service", service.Title, service.RejectionReason)
+	}
+
 	c.JSON(http.StatusOK, service)
 }
 
@@ -117,7 +277,7 @@ type ServiceRequestDto struct {
 	Category    string  `json:"category" binding:"required"`
 }
 
-// CreateServiceRequest creates a new service request
+// CreateServiceRequest creates a new service request. It is moderated before other users see it.
 func CreateServiceRequest(c *gin.Context) {
 	user := c.MustGet("user").(models.User)
 
@@ -134,10 +294,15 @@ func CreateServiceRequest(c *gin.Context) {
 		Description: req.Description,
 		Budget:      req.Budget,
 		Category:    req.Category,
-		Status:      "open",
+		Status:      "pending",
+	}
+
+	if err := database.DB.Create(&serviceRequest).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create service request"})
+		return
 	}
 
-	database.DB.Create(&serviceRequest)
+	moderateServiceRequest(&serviceRequest, user)
 	c.JSON(http.StatusCreated, serviceRequest)
 }
 
@@ -146,6 +311,7 @@ func ListServiceRequests(c *gin.Context) {
 	var serviceRequests []models.ServiceRequest
 
 	database.DB.Where("status = ?", "open").
+		Scopes(visibleOwner("requester_id")).
 		Preload("Requester").
 		Preload("Hostel").
 		Find(&serviceRequests)
@@ -179,7 +345,7 @@ func GetMyServiceRequests(c *gin.Context) {
 	c.JSON(http.StatusOK, serviceRequests)
 }
 
-// GetServiceRequestsITook returns all service requests the authenticated user has accepted
+// GetServiceRequestsITook returns all service requests the authenticated user was chosen for
 func GetServiceRequestsITook(c *gin.Context) {
 	user := c.MustGet("user").(models.User)
 	var serviceRequests []models.ServiceRequest
@@ -187,47 +353,6 @@ func GetServiceRequestsITook(c *gin.Context) {
 	c.JSON(http.StatusOK, serviceRequests)
 }
 
-// AcceptServiceRequest allows a provider to accept a service request
-func AcceptServiceRequest(c *gin.Context) {
-	user := c.MustGet("user").(models.User)
-
-	var serviceRequest models.ServiceRequest
-	if err := database.DB.First(&serviceRequest, c.Param("id")).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Service request not found"})
-		return
-	}
-
-	// Check if request is still open
-	if serviceRequest.Status != "open" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Service request is not open"})
-		return
-	}
-
-	// Check if user is not the requester
-	if serviceRequest.RequesterID == user.ID {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot fulfill your own request"})
-		return
-	}
-
-	// Update the service request
-	now := time.Now()
-	serviceRequest.Status = "in-progress"
-	serviceRequest.ProviderID = &user.ID
-	serviceRequest.AcceptedAt = &now
-	database.DB.Save(&serviceRequest)
-
-	// Load requester details to share contact info
-	var requester models.User
-	database.DB.First(&requester, serviceRequest.RequesterID)
-
-	c.JSON(http.StatusOK, gin.H{
-		"message":           "Service request accepted successfully",
-		"request":           serviceRequest,
-		"requester_contact": requester.ContactDetails,
-		"provider_contact":  user.ContactDetails,
-	})
-}
-
 // CompleteServiceRequest allows a requester to mark a service as completed
 func CompleteServiceRequest(c *gin.Context) {
 	user := c.MustGet("user").(models.User)
@@ -288,6 +413,8 @@ func CancelServiceRequest(c *gin.Context) {
 	serviceRequest.Status = "cancelled"
 	database.DB.Save(&serviceRequest)
 
+	declineOpenProposals(serviceRequest.ID, "The requester cancelled the request.")
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Service request cancelled",
 		"request": serviceRequest,
@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"OpenEx-Backend/internal/database"
+	"OpenEx-Backend/internal/models"
+	"OpenEx-Backend/internal/services/audit"
+	"OpenEx-Backend/internal/services/notify"
+	"OpenEx-Backend/internal/worker"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// editableServiceStatuses are the statuses in which the owner can edit a service. Rejected
+// services go through ResubmitService, expired ones are renewed first.
+var editableServiceStatuses = []string{"pending", "needs_review", "approved", "paused"}
+
+// errServiceChanged is returned when a service's status changed before an owner action applied
+var errServiceChanged = errors.New("service status changed")
+
+// UpdateServiceRequest is the request payload for editing a service. Omitted fields are kept.
+type UpdateServiceRequest struct {
+	Title       *string  `json:"title"`
+	Description *string  `json:"description"`
+	Price       *float64 `json:"price" binding:"omitempty,min=0"`
+	Category    *string  `json:"category"`
+}
+
+// UpdateService lets the owner edit a service. Price changes apply right away; changes to the
+// title, description or category send the service back to moderation.
+func UpdateService(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var req UpdateServiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Title == nil && req.Description == nil && req.Price == nil && req.Category == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nothing to update"})
+		return
+	}
+
+	service, ok := loadOwnedService(c, user, "edit")
+	if !ok {
+		return
+	}
+
+	if !slices.Contains(editableServiceStatuses, service.Status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This service can't be edited", "status": service.Status})
+		return
+	}
+
+	// Only the edited columns are written, so nothing else the service went through meanwhile is undone
+	updates := map[string]interface{}{}
+	for _, field := range []struct {
+		value   *string
+		current string
+		name    string
+	}{
+		{req.Title, service.Title, "title"},
+		{req.Description, service.Description, "description"},
+		{req.Category, service.Category, "category"},
+	} {
+		if field.value == nil {
+			continue
+		}
+		value := strings.TrimSpace(*field.value)
+		if value == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": field.name + " can't be empty"})
+			return
+		}
+		if value != field.current {
+			updates[field.name] = value
+		}
+	}
+	contentChanged := len(updates) > 0
+	if req.Price != nil && *req.Price != service.Price {
+		updates["price"] = *req.Price
+	}
+	if len(updates) == 0 {
+		c.JSON(http.StatusOK, service)
+		return
+	}
+
+	previousStatus := service.Status
+	if contentChanged {
+		now := time.Now()
+		updates["status"] = "pending"
+		updates["rejection_reason"] = ""
+		updates["review_reason"] = ""
+		updates["review_priority"] = 0
+		updates["submitted_at"] = &now
+	}
+
+	// The status condition keeps a moderator's decision or a pause made meanwhile from being overwritten
+	result := database.DB.Model(&service).Omit(clause.Associations).Where("status = ?", previousStatus).Updates(updates)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update service"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "The service changed meanwhile; reload it and try again"})
+		return
+	}
+
+	audit.Record(audit.UserActor(user), audit.Entry{
+		Action:     "service.update",
+		TargetType: "service",
+		TargetID:   service.ID,
+		FromStatus: previousStatus,
+		ToStatus:   service.Status,
+	})
+
+	if contentChanged {
+		worker.ServiceSubmitted(&service)
+	}
+
+	c.JSON(http.StatusOK, service)
+}
+
+// PauseService hides an approved service from the public list until the owner resumes it.
+// Existing bookings stay as they are, but its slots can't be booked while it is paused.
+func PauseService(c *gin.Context) {
+	changeServiceStatus(c, "approved", "paused", "service.pause")
+}
+
+// ResumeService makes a paused service public again
+func ResumeService(c *gin.Context) {
+	changeServiceStatus(c, "paused", "approved", "service.resume")
+}
+
+// changeServiceStatus moves the authenticated user's service from one status to another
+func changeServiceStatus(c *gin.Context, from, to, action string) {
+	user := c.MustGet("user").(models.User)
+
+	verb := strings.TrimPrefix(action, "service.")
+	service, ok := loadOwnedService(c, user, verb)
+	if !ok {
+		return
+	}
+
+	// The status condition keeps a service that was edited or moderated meanwhile untouched
+	result := database.DB.Model(&models.Service{}).
+		Where("id = ? AND status = ?", service.ID, from).
+		Update("status", to)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to " + verb + " service"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only " + from + " services can be " + verb + "d", "status": service.Status})
+		return
+	}
+	service.Status = to
+
+	audit.Record(audit.UserActor(user), audit.Entry{
+		Action:     action,
+		TargetType: "service",
+		TargetID:   service.ID,
+		FromStatus: from,
+		ToStatus:   to,
+	})
+
+	c.JSON(http.StatusOK, service)
+}
+
+// DeleteService withdraws the authenticated user's service. Its slots are removed and active
+// bookings are cancelled; the record stays for the history of past bookings.
+func DeleteService(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	service, ok := loadOwnedService(c, user, "delete")
+	if !ok {
+		return
+	}
+
+	const reason = "The provider deleted the service."
+	now := time.Now()
+	previousStatus := service.Status
+	var cancelled []models.Booking
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Service{}).
+			Where("id = ? AND status = ?", service.ID, previousStatus).
+			Update("status", "removed")
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errServiceChanged
+		}
+
+		if err := tx.Model(&models.ServiceSlot{}).
+			Where("service_id = ? AND status IN ?", service.ID, []string{"open", "booked"}).
+			Update("status", "removed").Error; err != nil {
+			return err
+		}
+
+		if err := tx.Preload("Requester").
+			Where("service_id = ? AND status IN ?", service.ID, activeBookingStatuses).
+			Find(&cancelled).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Booking{}).
+			Where("service_id = ? AND status IN ?", service.ID, activeBookingStatuses).
+			Updates(map[string]interface{}{
+				"status":        "cancelled",
+				"cancel_reason": reason,
+				"cancelled_by":  user.ID,
+				"cancelled_at":  now,
+			}).Error
+	})
+	if errors.Is(err, errServiceChanged) {
+		c.JSON(http.StatusConflict, gin.H{"error": "The service changed meanwhile, please try again"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete service"})
+		return
+	}
+
+	audit.Record(audit.UserActor(user), audit.Entry{
+		Action:     "service.delete",
+		TargetType: "service",
+		TargetID:   service.ID,
+		FromStatus: previousStatus,
+		ToStatus:   "removed",
+	})
+
+	for _, booking := range cancelled {
+		audit.Record(audit.UserActor(user), audit.Entry{
+			Action:     "booking.cancel",
+			TargetType: "booking",
+			TargetID:   booking.ID,
+			FromStatus: booking.Status,
+			ToStatus:   "cancelled",
+			Reason:     reason,
+		})
+		go notify.BookingCancelled(booking.Requester, service.Title, booking.StartsAt, reason)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Service deleted", "cancelledBookings": len(cancelled)})
+}
+
+// loadOwnedService loads a service that is not removed and belongs to the authenticated user.
+// verb names the attempted action in the error shown to anyone else.
+func loadOwnedService(c *gin.Context, user models.User, verb string) (models.Service, bool) {
+	var service models.Service
+	if err := database.DB.First(&service, c.Param("id")).Error; err != nil || service.Status == "removed" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
+		return service, false
+	}
+
+	if service.UserID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the owner can " + verb + " this service"})
+		return service, false
+	}
+	return service, true
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"OpenEx-Backend/internal/database"
+	"OpenEx-Backend/internal/models"
+	"OpenEx-Backend/internal/rbac"
+	"OpenEx-Backend/internal/services/audit"
+	"OpenEx-Backend/internal/services/moderationlog"
+	"OpenEx-Backend/internal/services/moderator"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+)
+
+// submission is a loaded offer, requested item or service request that is moderated before
+// other users see it. They share the status, rejection_reason, review_reason and
+// review_priority columns, and open with the status "open".
+type submission struct {
+	Row      interface{} // Pointer to the loaded model; decisions are written back into it
+	Kind     string      // Moderation kind, also the audit and moderation log target type
+	Label    string      // Shown in errors, e.g. "Offer"
+	ID       uint
+	HostelID uint
+	Status   string
+
+	// Notify tells the author their submission was rejected and why
+	Notify func(reason string)
+}
+
+// moderationColumns are the columns a moderation decision sets on a submission
+func moderationColumns(status, rejectionReason, reviewReason string, reviewPriority int) map[string]interface{} {
+	return map[string]interface{}{
+		"status":           status,
+		"rejection_reason": rejectionReason,
+		"review_reason":    reviewReason,
+		"review_priority":  reviewPriority,
+	}
+}
+
+// moderateSubmission runs a new submission through its moderation pipeline. Clean submissions
+// open right away, borderline ones wait for a moderator and offending ones are rejected. It
+// reports whether the outcome was saved; a submission that left its status meanwhile is kept.
+func moderateSubmission(s submission, content moderator.Content) bool {
+	result := moderator.Evaluate(s.Kind, content)
+
+	action, columns := s.Kind+".auto_approve", moderationColumns("open", "", "", 0)
+	switch result.Outcome {
+	case moderator.OutcomeReject:
+		action, columns = s.Kind+".auto_reject", moderationColumns("rejected", result.Reason, "", 0)
+	case moderator.OutcomeReview:
+		action, columns = s.Kind+".flag", moderationColumns("needs_review", "", result.Reason, result.Priority)
+	}
+
+	update := database.DB.Model(s.Row).Omit(clause.Associations).Where("status = ?", s.Status).Updates(columns)
+	if update.Error != nil {
+		log.Printf("Error updating %s #%d: %v", s.Kind, s.ID, update.Error)
+		return false
+	}
+	if update.RowsAffected == 0 {
+		return false
+	}
+
+	moderationlog.RecordAutomatic(s.Kind, s.ID, result)
+	audit.Record(audit.WorkerActor("submission-moderator"), audit.Entry{
+		Action:     action,
+		TargetType: s.Kind,
+		TargetID:   s.ID,
+		FromStatus: s.Status,
+		ToStatus:   columns["status"].(string),
+		Reason:     strings.TrimSpace(fmt.Sprintf("%s (safety: %.2f)", result.Reason, result.Safety)),
+	})
+
+	if result.Outcome == moderator.OutcomeReject {
+		go s.Notify(result.Reason)
+	}
+	return true
+}
+
+// decideSubmission records a moderator's decision on a submission waiting for review and
+// writes the error response when it cannot. The caller sends the response on success.
+func decideSubmission(c *gin.Context, s submission, approved bool) bool {
+	user := c.MustGet("user").(models.User)
+
+	// The reason is optional; an empty body falls back to the default reason
+	var req RejectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+
+	if !rbac.CanAccessHostel(user, s.HostelID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("You can only moderate %ss in your hostel", strings.ToLower(s.Label))})
+		return false
+	}
+
+	if s.Status != "pending" && s.Status != "needs_review" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": s.Label + " is not waiting for moderation", "status": s.Status})
+		return false
+	}
+
+	action, decision, columns := s.Kind+".approve", "approved", moderationColumns("open", "", "", 0)
+	reason := ""
+	if !approved {
+		reason = strings.TrimSpace(req.Reason)
+		if reason == "" {
+			reason = defaultRejectionReason
+		}
+		action, decision, columns = s.Kind+".reject", "rejected", moderationColumns("rejected", reason, "", 0)
+	}
+
+	// Only the first of two moderators deciding at once gets through
+	update := database.DB.Model(s.Row).Omit(clause.Associations).Where("status IN ?", moderatableStatuses).Updates(columns)
+	if update.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update " + strings.ToLower(s.Label)})
+		return false
+	}
+	if update.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": s.Label + " was decided meanwhile"})
+		return false
+	}
+
+	audit.Record(audit.UserActor(user), audit.Entry{
+		Action:     action,
+		TargetType: s.Kind,
+		TargetID:   s.ID,
+		FromStatus: s.Status,
+		ToStatus:   columns["status"].(string),
+		Reason:     reason,
+	})
+	moderationlog.RecordHuman(s.Kind, s.ID, user, decision, reason)
+
+	if !approved {
+		go s.Notify(reason)
+	}
+	return true
+}
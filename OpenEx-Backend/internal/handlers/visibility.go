@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"time"
+
+	"OpenEx-Backend/internal/database"
+	"OpenEx-Backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// restrictedUserIDs selects the IDs of users whose content must not be shown publicly, including
+// deleted accounts
+func restrictedUserIDs() *gorm.DB {
+	return database.DB.Model(&models.User{}).
+		Select("id").
+		Where("banned_at IS NOT NULL OR suspended_until > ? OR hidden_at IS NOT NULL OR anonymized_at IS NOT NULL", time.Now())
+}
+
+// visibleOwner is a query scope that hides records owned by suspended, banned, reported or
+// deleted users, as well as records hidden after reports. column is the owner column of the
+// queried table, e.g. "user_id" or "buyer_id".
+func visibleOwner(column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(column+" NOT IN (?)", restrictedUserIDs()).Where("hidden_at IS NULL")
+	}
+}
@@ -9,6 +9,7 @@ import (
     "github.com/golang-jwt/jwt/v5"
     "OpenEx-Backend/internal/database"
     "OpenEx-Backend/internal/models"
+    "OpenEx-Backend/internal/rbac"
 )
 
 // Auth middleware for authenticating requests
@@ -38,21 +39,75 @@ func Auth() gin.HandlerFunc {
             return
         }
 
+        userID, ok := claims["user_id"].(float64)
+        if !ok {
+            c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+            return
+        }
+
         var user models.User
-        database.DB.First(&user, uint(claims["user_id"].(float64)))
+        if err := database.DB.First(&user, uint(userID)).Error; err != nil || user.AnonymizedAt != nil {
+            c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Account no longer exists"})
+            return
+        }
+
+        if restricted, details := AccountRestriction(user); restricted {
+            c.AbortWithStatusJSON(http.StatusForbidden, details)
+            return
+        }
         c.Set("user", user)
         c.Next()
     }
 }
 
-// Admin middleware for admin-only routes
+// AccountRestriction describes why a banned or suspended user may not use their account.
+// Login and every authenticated request answer with the same details.
+func AccountRestriction(user models.User) (bool, gin.H) {
+    if user.IsBanned() {
+        return true, gin.H{"error": "Your account has been banned", "reason": user.RestrictionReason}
+    }
+    if user.IsSuspended() {
+        return true, gin.H{
+            "error":           "Your account is suspended",
+            "reason":          user.RestrictionReason,
+            "suspended_until": user.SuspendedUntil,
+        }
+    }
+    return false, nil
+}
+
+// Admin middleware for super-admin-only routes
 func Admin() gin.HandlerFunc {
     return func(c *gin.Context) {
         user := c.MustGet("user").(models.User)
-        if user.Role != "admin" {
+        if user.Role != rbac.RoleAdmin {
             c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
             return
         }
         c.Next()
     }
+}
+
+// Staff middleware for routes open to any administrative role
+func Staff() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        user := c.MustGet("user").(models.User)
+        if !rbac.IsStaff(user.Role) {
+            c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Staff access required"})
+            return
+        }
+        c.Next()
+    }
+}
+
+// RequirePermission middleware rejects users whose role doesn't grant the permission
+func RequirePermission(permission rbac.Permission) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        user := c.MustGet("user").(models.User)
+        if !rbac.HasPermission(user.Role, permission) {
+            c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Missing permission: " + string(permission)})
+            return
+        }
+        c.Next()
+    }
 }
\ No newline at end of file
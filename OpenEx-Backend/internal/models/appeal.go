@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+// Appeal is an owner's request to reconsider the rejection of an item or service
+type Appeal struct {
+	ID              uint       `gorm:"primaryKey"`
+	TargetType      string     `gorm:"not null;index:idx_appeal_target;uniqueIndex:idx_appeal_round"` // item, service
+	TargetID        uint       `gorm:"not null;index:idx_appeal_target;uniqueIndex:idx_appeal_round"`
+	SubmittedAt     *time.Time `gorm:"uniqueIndex:idx_appeal_round"` // Start of the moderation round appealed against, so each rejection is appealed once
+	UserID          uint       `gorm:"not null;index"`
+	User            User       `gorm:"foreignKey:UserID"`
+	HostelID        uint       `gorm:"index"` // Hostel of the listing, used to scope hostel moderators
+	Message         string     `gorm:"type:text;not null"`
+	RejectionReason string     `gorm:"type:text"`               // The reason the appeal is against
+	Status          string     `gorm:"default:'pending';index"` // pending, granted, denied
+	DecidedByID     *uint
+	DecisionNote    string `gorm:"type:text"`
+	DecidedAt       *time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrAuditLogAppendOnly is returned when something tries to modify an existing audit entry
+var ErrAuditLogAppendOnly = errors.New("audit log entries are append-only")
+
+type AuditLog struct {
+	ID         uint   `gorm:"primaryKey"`
+	ActorType  string `gorm:"not null;index"` // user, worker
+	ActorID    *uint  `gorm:"index"`          // Set when ActorType is user
+	ActorName  string `gorm:"not null"`
+	Action     string `gorm:"not null;index"` // e.g. item.approve, user.ban
+	TargetType string `gorm:"not null;index:idx_audit_target"`
+	TargetID   uint   `gorm:"not null;index:idx_audit_target"`
+	FromStatus string
+	ToStatus   string
+	Reason     string    `gorm:"type:text"`
+	CreatedAt  time.Time `gorm:"index"`
+}
+
+// BeforeUpdate keeps audit entries immutable
+func (AuditLog) BeforeUpdate(tx *gorm.DB) error {
+	return ErrAuditLogAppendOnly
+}
+
+// BeforeDelete keeps audit entries immutable
+func (AuditLog) BeforeDelete(tx *gorm.DB) error {
+	return ErrAuditLogAppendOnly
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+)
+
+// ServiceSlot is a time a provider is available to deliver one of their services. A slot
+// holds one booking at a time.
+type ServiceSlot struct {
+	ID         uint      `gorm:"primaryKey"`
+	ServiceID  uint      `gorm:"not null;index"`
+	Service    Service   `gorm:"foreignKey:ServiceID"`
+	ProviderID uint      `gorm:"not null;index"`
+	StartsAt   time.Time `gorm:"not null;index"`
+	EndsAt     time.Time `gorm:"not null"`
+	Status     string    `gorm:"default:'open';index"` // open, booked, removed
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// Booking is a requester's reservation of a service slot
+type Booking struct {
+	ID           uint        `gorm:"primaryKey"`
+	SlotID       uint        `gorm:"not null;index"`
+	Slot         ServiceSlot `gorm:"foreignKey:SlotID"`
+	ServiceID    uint        `gorm:"not null;index"`
+	Service      Service     `gorm:"foreignKey:ServiceID"`
+	RequesterID  uint        `gorm:"not null;index"`
+	Requester    User        `gorm:"foreignKey:RequesterID"`
+	ProviderID   uint        `gorm:"not null;index"`
+	Provider     User        `gorm:"foreignKey:ProviderID"`
+	StartsAt     time.Time   `gorm:"not null;index"` // Copied from the slot, so the booking keeps its time if the slot changes
+	EndsAt       time.Time   `gorm:"not null"`
+	Note         string      `gorm:"type:text"`                 // What the requester wants covered
+	Status       string      `gorm:"default:'requested';index"` // requested, confirmed, cancelled, completed
+	CancelReason string      `gorm:"type:text"`
+	CancelledBy  *uint
+	ConfirmedAt  *time.Time
+	CancelledAt  *time.Time
+	CompletedAt  *time.Time
+	RemindedAt   *time.Time // When both parties were reminded of the upcoming session
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+// CalendarFeed holds the secret token in a user's private iCalendar feed URL. Resetting the
+// feed replaces the token, which stops the old URL from working.
+type CalendarFeed struct {
+	ID            uint       `gorm:"primaryKey"`
+	UserID        uint       `gorm:"not null;uniqueIndex"`
+	Token         string     `gorm:"size:64;not null;uniqueIndex"`
+	LastFetchedAt *time.Time // When a calendar app last pulled the feed
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+)
+
+type EmailChange struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null"`
+	User      User      `gorm:"foreignKey:UserID"`
+	NewEmail  string    `gorm:"not null"`
+	Token     string    `gorm:"not null;unique"`
+	ExpiresAt time.Time `gorm:"not null"`
+	Used      bool      `gorm:"default:false"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
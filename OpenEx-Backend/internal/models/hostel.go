@@ -7,6 +7,7 @@ import (
 type Hostel struct {
     ID        uint   `gorm:"primaryKey"`
     Name      string `gorm:"unique;not null"`
+    Zone      string // Hostels sharing a zone count as nearby, e.g. for matching requests
     CreatedAt time.Time
     UpdatedAt time.Time
 }
\ No newline at end of file
@@ -5,18 +5,28 @@ import (
 )
 
 type Item struct {
-	ID          uint   `gorm:"primaryKey"`
-	Title       string `gorm:"not null"`
-	Description string `gorm:"not null"`
-	Price       float64
-	Image       string
-	Type        string `gorm:"not null"`
-	Status      string `gorm:"default:'pending'"`
-	Quantity    int    `gorm:"default:1"`
-	UserID      uint   `gorm:"not null"`
-	User        User
-	HostelID    uint   `gorm:"not null"`
-	Hostel      Hostel `gorm:"foreignKey:HostelID"`
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ID               uint   `gorm:"primaryKey"`
+	Title            string `gorm:"not null"`
+	Description      string `gorm:"not null"`
+	Price            float64
+	Image            string
+	ImageHash        string     `gorm:"size:16;index"` // Perceptual hash of Image, used to spot duplicate listings
+	Type             string     `gorm:"not null"`
+	Status           string     `gorm:"default:'pending'"`
+	RejectionReason  string     `gorm:"type:text"`       // Shown to the owner when Status is rejected
+	ReviewReason     string     `gorm:"type:text"`       // Why automatic moderation held the item back for a human
+	ReviewPriority   int        `gorm:"default:0;index"` // Higher values are reviewed first when Status is needs_review
+	SubmittedAt      *time.Time // Last time the item was (re)submitted for moderation
+	HiddenAt         *time.Time // Hidden from public lists after repeated reports, until staff review it
+	ExpiresAt        *time.Time `gorm:"index"` // Moved to expired after this unless the owner renews it
+	ExpiryRemindedAt *time.Time // When the owner was reminded of the upcoming expiry
+	ClaimToken       string     `gorm:"size:32;index" json:"-"` // Set while a worker instance is processing the row
+	ClaimedUntil     *time.Time `json:"-"`                      // When the claim expires if the worker never releases it
+	Quantity         int        `gorm:"default:1"`
+	UserID           uint       `gorm:"not null"`
+	User             User
+	HostelID         uint   `gorm:"not null"`
+	Hostel           Hostel `gorm:"foreignKey:HostelID"`
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
 }
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+)
+
+// ModerationResult records one moderation decision about a submitted item or service
+type ModerationResult struct {
+	ID         uint    `gorm:"primaryKey"`
+	TargetType string  `gorm:"not null;index:idx_moderation_target"` // item, service
+	TargetID   uint    `gorm:"not null;index:idx_moderation_target"`
+	Decision   string  `gorm:"not null"`       // approved, rejected, needs_review
+	Source     string  `gorm:"not null;index"` // automatic, human
+	ActorID    *uint   // Staff member for human decisions
+	Confidence float64 // Combined safety score for automatic decisions, higher is safer
+	Reason     string  `gorm:"type:text"`
+	Providers  []ModerationProviderScore
+	CreatedAt  time.Time `gorm:"index"`
+}
+
+// ModerationProviderScore is what one provider answered as part of an automatic decision
+type ModerationProviderScore struct {
+	ID                 uint    `gorm:"primaryKey"`
+	ModerationResultID uint    `gorm:"not null;index"`
+	Provider           string  `gorm:"not null;index"`
+	Verdict            string  `gorm:"not null"` // approved, rejected, flagged, skipped, error
+	Score              float64 // Safety score, higher is safer
+	Reason             string  `gorm:"type:text"`
+	Error              string  `gorm:"type:text"`
+}
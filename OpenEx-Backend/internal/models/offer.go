@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// Offer is a seller's answer to a requested item. The buyer compares the offers on their
+// request and accepts one, which turns it into a transaction request.
+type Offer struct {
+	ID                   uint          `gorm:"primaryKey"`
+	RequestedItemID      uint          `gorm:"not null;index"`
+	RequestedItem        RequestedItem `gorm:"foreignKey:RequestedItemID"`
+	SellerID             uint          `gorm:"not null;index"`
+	Seller               User          `gorm:"foreignKey:SellerID"`
+	HostelID             uint          `gorm:"index"` // Seller's hostel, used to scope hostel moderators
+	Price                float64
+	Quantity             int    `gorm:"default:1"`
+	Message              string `gorm:"type:text"`
+	Image                string
+	Status               string `gorm:"default:'pending';index"` // pending, needs_review, open, rejected, accepted, declined, withdrawn, cancelled
+	RejectionReason      string `gorm:"type:text"`               // Shown to the seller when Status is rejected
+	ReviewReason         string `gorm:"type:text"`               // Why automatic moderation held the offer back for a human
+	ReviewPriority       int    `gorm:"default:0;index"`
+	ItemID               *uint  // Listing created for the buyer when the offer is accepted
+	TransactionRequestID *uint
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+// Proposal is a provider's bid on a service request. The requester compares the proposals on
+// their request and chooses one, which hands the job to that provider.
+type Proposal struct {
+	ID                  uint           `gorm:"primaryKey"`
+	ServiceRequestID    uint           `gorm:"not null;index"`
+	ServiceRequest      ServiceRequest `gorm:"foreignKey:ServiceRequestID"`
+	ProviderID          uint           `gorm:"not null;index"`
+	Provider            User           `gorm:"foreignKey:ProviderID"`
+	Price               float64
+	EstimatedCompletion time.Time // When the provider expects to be done
+	Message             string    `gorm:"type:text"`
+	Status              string    `gorm:"default:'open';index"` // open, accepted, declined, withdrawn
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
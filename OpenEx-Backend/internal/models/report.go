@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// Report is a user's complaint about a listing or another user
+type Report struct {
+	ID           uint   `gorm:"primaryKey"`
+	ReporterID   uint   `gorm:"not null;uniqueIndex:idx_report_reporter_target"` // Each user reports a target once
+	Reporter     User   `gorm:"foreignKey:ReporterID"`
+	TargetType   string `gorm:"not null;index:idx_report_target;uniqueIndex:idx_report_reporter_target"` // item, service, requested_item, service_request, user
+	TargetID     uint   `gorm:"not null;index:idx_report_target;uniqueIndex:idx_report_reporter_target"`
+	HostelID     uint   `gorm:"index"`    // Hostel of the reported content, used to scope hostel moderators
+	Category     string `gorm:"not null"` // scam, prohibited, offensive, harassment, spam, other
+	Details      string `gorm:"type:text"`
+	Status       string `gorm:"default:'open';index"` // open, dismissed, upheld
+	ResolvedByID *uint
+	Resolution   string `gorm:"type:text"`
+	ResolvedAt   *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
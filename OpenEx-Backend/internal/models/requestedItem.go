@@ -5,16 +5,31 @@ import (
 )
 
 type RequestedItem struct {
-	ID          uint   `gorm:"primaryKey"`
-	BuyerID     uint   `gorm:"not null"`
-	Buyer       User   `gorm:"foreignKey:BuyerID"`
-	HostelID    uint   `gorm:"not null"`
-	Hostel      Hostel `gorm:"foreignKey:HostelID"`
-	Title       string `gorm:"not null"`
-	Description string `gorm:"not null"`
-	MaxPrice    float64
-	Quantity    int    `gorm:"default:1"`      // Add Quantity field
-	Status      string `gorm:"default:'open'"` // open, fulfilled, closed
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ID                uint   `gorm:"primaryKey"`
+	BuyerID           uint   `gorm:"not null"`
+	Buyer             User   `gorm:"foreignKey:BuyerID"`
+	HostelID          uint   `gorm:"not null"`
+	Hostel            Hostel `gorm:"foreignKey:HostelID"`
+	Title             string `gorm:"not null"`
+	Description       string `gorm:"not null"`
+	MaxPrice          float64
+	Quantity          int        `gorm:"default:1"`         // How many the buyer wants in total
+	FulfilledQuantity int        `gorm:"default:0"`         // How many accepted offers cover so far
+	Status            string     `gorm:"default:'pending'"` // pending, needs_review, open, rejected, fulfilled, closed, expired
+	RejectionReason   string     `gorm:"type:text"`         // Shown to the buyer when Status is rejected
+	ReviewReason      string     `gorm:"type:text"`         // Why automatic moderation held the request back for a human
+	ReviewPriority    int        `gorm:"default:0;index"`   // Higher values are reviewed first when Status is needs_review
+	HiddenAt          *time.Time // Hidden from public lists after repeated reports, until staff review it
+	ExpiresAt         *time.Time `gorm:"index"` // Moved to expired after this unless the owner renews it
+	ExpiryRemindedAt  *time.Time // When the owner was reminded of the upcoming expiry
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// RemainingQuantity returns how many the buyer still needs
+func (r RequestedItem) RemainingQuantity() int {
+	if remaining := r.Quantity - r.FulfilledQuantity; remaining > 0 {
+		return remaining
+	}
+	return 0
 }
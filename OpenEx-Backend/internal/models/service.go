@@ -5,16 +5,25 @@ import (
 )
 
 type Service struct {
-	ID          uint   `gorm:"primaryKey"`
-	UserID      uint   `gorm:"not null"`
-	User        User   `gorm:"foreignKey:UserID"`
-	HostelID    uint   `gorm:"not null"`
-	Hostel      Hostel `gorm:"foreignKey:HostelID"`
-	Title       string `gorm:"not null"`
-	Description string `gorm:"not null"`
-	Price       float64
-	Category    string `gorm:"not null"` // e.g., "notes", "tutoring", "project"
-	Status      string `gorm:"default:'pending'"`
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ID               uint   `gorm:"primaryKey"`
+	UserID           uint   `gorm:"not null"`
+	User             User   `gorm:"foreignKey:UserID"`
+	HostelID         uint   `gorm:"not null"`
+	Hostel           Hostel `gorm:"foreignKey:HostelID"`
+	Title            string `gorm:"not null"`
+	Description      string `gorm:"not null"`
+	Price            float64
+	Category         string     `gorm:"not null"` // e.g., "notes", "tutoring", "project"
+	Status           string     `gorm:"default:'pending'"`
+	RejectionReason  string     `gorm:"type:text"`       // Shown to the owner when Status is rejected
+	ReviewReason     string     `gorm:"type:text"`       // Why automatic moderation held the service back for a human
+	ReviewPriority   int        `gorm:"default:0;index"` // Higher values are reviewed first when Status is needs_review
+	SubmittedAt      *time.Time // Last time the service was (re)submitted for moderation
+	HiddenAt         *time.Time // Hidden from public lists after repeated reports, until staff review it
+	ExpiresAt        *time.Time `gorm:"index"` // Moved to expired after this unless the owner renews it
+	ExpiryRemindedAt *time.Time // When the owner was reminded of the upcoming expiry
+	ClaimToken       string     `gorm:"size:32;index" json:"-"` // Set while a worker instance is processing the row
+	ClaimedUntil     *time.Time `json:"-"`                      // When the claim expires if the worker never releases it
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
 }
@@ -5,20 +5,29 @@ import (
 )
 
 type ServiceRequest struct {
-	ID          uint   `gorm:"primaryKey"`
-	RequesterID uint   `gorm:"not null"`
-	Requester   User   `gorm:"foreignKey:RequesterID"`
-	HostelID    uint   `gorm:"not null"`
-	Hostel      Hostel `gorm:"foreignKey:HostelID"`
-	Title       string `gorm:"not null"`
-	Description string `gorm:"not null"`
-	Budget      float64
-	Category    string `gorm:"not null"` // e.g., "notes", "tutoring", "project"
-	Status      string `gorm:"default:'open'"`
-	ProviderID  *uint
-	Provider    User `gorm:"foreignKey:ProviderID"`
-	AcceptedAt  *time.Time
-	CompletedAt *time.Time
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ID               uint   `gorm:"primaryKey"`
+	RequesterID      uint   `gorm:"not null"`
+	Requester        User   `gorm:"foreignKey:RequesterID"`
+	HostelID         uint   `gorm:"not null"`
+	Hostel           Hostel `gorm:"foreignKey:HostelID"`
+	Title            string `gorm:"not null"`
+	Description      string `gorm:"not null"`
+	Budget           float64
+	Category         string `gorm:"not null"`          // e.g., "notes", "tutoring", "project"
+	Status           string `gorm:"default:'pending'"` // pending, needs_review, open, rejected, in-progress, completed, cancelled, expired
+	RejectionReason  string `gorm:"type:text"`         // Shown to the requester when Status is rejected
+	ReviewReason     string `gorm:"type:text"`         // Why automatic moderation held the request back for a human
+	ReviewPriority   int    `gorm:"default:0;index"`   // Higher values are reviewed first when Status is needs_review
+	ProviderID       *uint
+	Provider         User       `gorm:"foreignKey:ProviderID"`
+	ProposalID       *uint      // Proposal the requester chose
+	AgreedPrice      float64    // Price of the chosen proposal
+	DueAt            *time.Time // Estimated completion of the chosen proposal
+	AcceptedAt       *time.Time
+	CompletedAt      *time.Time
+	HiddenAt         *time.Time // Hidden from public lists after repeated reports, until staff review it
+	ExpiresAt        *time.Time `gorm:"index"` // Moved to expired after this unless the owner renews it
+	ExpiryRemindedAt *time.Time // When the owner was reminded of the upcoming expiry
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
 }
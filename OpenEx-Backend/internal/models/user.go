@@ -5,14 +5,30 @@ import (
 )
 
 type User struct {
-    ID             uint   `gorm:"primaryKey"`
-    Name           string `gorm:"not null"`
-    Email          string `gorm:"unique;not null"`
-    Password       string `gorm:"not null"`
-    ContactDetails string `gorm:"not null"`
-    Role           string `gorm:"default:'user'"`
-    HostelID       uint
-    Hostel         Hostel `gorm:"foreignKey:HostelID"`
-    CreatedAt      time.Time
-    UpdatedAt      time.Time
+    ID                uint   `gorm:"primaryKey"`
+    Name              string `gorm:"not null"`
+    Email             string `gorm:"unique;not null"`
+    Password          string `gorm:"not null"`
+    ContactDetails    string `gorm:"not null"`
+    Role              string `gorm:"default:'user'"` // user, admin, hostel_moderator, support
+    HostelID          uint
+    Hostel            Hostel `gorm:"foreignKey:HostelID"`
+    ModeratedHostelID *uint      // Hostel a hostel_moderator is allowed to moderate
+    SuspendedUntil    *time.Time // Account is locked until this time
+    BannedAt          *time.Time // Account is locked permanently
+    RestrictionReason string     // Why the account was suspended or banned
+    AnonymizedAt      *time.Time // Set when the account is deleted; personal data is scrubbed
+    HiddenAt          *time.Time // Listings hidden after repeated reports, until staff review them
+    CreatedAt         time.Time
+    UpdatedAt         time.Time
+}
+
+// IsSuspended reports whether the user is inside a time-limited suspension
+func (u User) IsSuspended() bool {
+    return u.SuspendedUntil != nil && u.SuspendedUntil.After(time.Now())
+}
+
+// IsBanned reports whether the user has been banned
+func (u User) IsBanned() bool {
+    return u.BannedAt != nil
 }
\ No newline at end of file
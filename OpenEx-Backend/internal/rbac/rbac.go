@@ -0,0 +1,120 @@
+package rbac
+
+import (
+	"OpenEx-Backend/internal/models"
+)
+
+// Roles that can be assigned to users
+const (
+	RoleUser            = "user"
+	RoleAdmin           = "admin" // Super-admin, can do everything
+	RoleHostelModerator = "hostel_moderator"
+	RoleSupport         = "support"
+)
+
+// Permission is a single capability that a route can require
+type Permission string
+
+const (
+	// ViewModerationQueue allows reading pending items, services, offers and requests
+	ViewModerationQueue Permission = "moderation:view"
+	// ModerateListings allows approving and rejecting items, services, offers and requests
+	ModerateListings Permission = "moderation:decide"
+	// ManageHostels allows creating hostels
+	ManageHostels Permission = "hostels:manage"
+	// ManageRoles allows assigning roles to users
+	ManageRoles Permission = "roles:manage"
+	// ViewUsers allows searching users and viewing their listings and transactions
+	ViewUsers Permission = "users:view"
+	// ManageUsers allows suspending, banning and reinstating users
+	ManageUsers Permission = "users:manage"
+	// ViewAuditLog allows reading the audit log of administrative actions
+	ViewAuditLog Permission = "audit:view"
+	// ManageModerationRules allows changing the keyword moderation rules
+	ManageModerationRules Permission = "moderation:rules"
+	// ViewReports allows reading user reports about listings and users
+	ViewReports Permission = "reports:view"
+	// ResolveReports allows dismissing or upholding user reports
+	ResolveReports Permission = "reports:resolve"
+	// ViewJobs allows reading the status and timings of background jobs
+	ViewJobs Permission = "jobs:view"
+)
+
+// rolePermissions maps each role to the permissions it grants
+var rolePermissions = map[string][]Permission{
+	RoleAdmin: {
+		ViewModerationQueue,
+		ModerateListings,
+		ManageHostels,
+		ManageRoles,
+		ViewUsers,
+		ManageUsers,
+		ViewAuditLog,
+		ManageModerationRules,
+		ViewReports,
+		ResolveReports,
+		ViewJobs,
+	},
+	RoleHostelModerator: {
+		ViewModerationQueue,
+		ModerateListings,
+		ViewReports,
+		ResolveReports,
+	},
+	RoleSupport: {
+		ViewModerationQueue,
+		ViewUsers,
+		ViewAuditLog,
+		ViewReports,
+		ViewJobs,
+	},
+	RoleUser: {},
+}
+
+// HasPermission reports whether the role grants the permission
+func HasPermission(role string, permission Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// IsStaff reports whether the role grants any administrative permission
+func IsStaff(role string) bool {
+	return len(rolePermissions[role]) > 0
+}
+
+// IsValidRole reports whether the role is known
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// Roles returns every role together with the permissions it grants
+func Roles() map[string][]Permission {
+	return rolePermissions
+}
+
+// CanAccessHostel reports whether a staff user may act on records belonging to a hostel.
+// Hostel moderators are limited to the hostel they were assigned; other staff roles are global.
+func CanAccessHostel(user models.User, hostelID uint) bool {
+	if user.Role != RoleHostelModerator {
+		return IsStaff(user.Role)
+	}
+	return user.ModeratedHostelID != nil && *user.ModeratedHostelID == hostelID
+}
+
+// HostelScope returns the hostel a staff user is restricted to, or nil if they can see every hostel
+func HostelScope(user models.User) *uint {
+	if user.Role == RoleHostelModerator {
+		if user.ModeratedHostelID == nil {
+			// A moderator without an assignment must not see anything
+			none := uint(0)
+			return &none
+		}
+		return user.ModeratedHostelID
+	}
+	return nil
+}
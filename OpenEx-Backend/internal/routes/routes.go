@@ -3,6 +3,7 @@ package routes
 import (
 	"OpenEx-Backend/internal/handlers"
 	"OpenEx-Backend/internal/middleware"
+	"OpenEx-Backend/internal/rbac"
 
 	"github.com/gin-gonic/gin"
 )
@@ -23,10 +24,14 @@ func SetupRouter() *gin.Engine {
 	r.GET("/hostels/:id/items", handlers.ListItemsByHostel)
 	r.GET("/requested-items", handlers.ListRequestedItems)
 	r.GET("/services", handlers.ListServices)
+	r.GET("/services/:id/slots", handlers.ListSlots)
+	r.GET("/calendar/feed/:token", handlers.ServeCalendarFeed)
 	r.GET("/service-requests", handlers.ListServiceRequests)
 	r.POST("/forgot-password", handlers.ForgotPassword)
 	r.GET("/validate-reset-token", handlers.ValidateResetToken)
 	r.POST("/reset-password", handlers.ResetPassword)
+	r.POST("/confirm-email-change", handlers.ConfirmEmailChange)
+	r.POST("/renew", handlers.RenewWithToken)
 	r.POST("/feedback", handlers.GetFeedback)
 
 	// Authenticated routes
@@ -35,29 +40,76 @@ func SetupRouter() *gin.Engine {
 	{
 		auth.POST("/items", handlers.CreateItem)
 		auth.GET("/items/:id", handlers.GetItem)
+		auth.PUT("/items/:id/resubmit", handlers.ResubmitItem)
+		auth.POST("/items/:id/report", handlers.ReportItem)
+		auth.POST("/items/:id/appeal", handlers.AppealItem)
+		auth.POST("/items/:id/renew", handlers.RenewItem)
+		auth.GET("/my-appeals", handlers.GetMyAppeals)
 		auth.POST("/requests", handlers.CreateRequest)
 		auth.GET("/requests", handlers.ListRequests)
 		auth.PATCH("/requests/:id/approve", handlers.ApproveRequest)
 		auth.GET("/my-items", handlers.GetUserItems)
 		auth.GET("/user", handlers.GetUserDetails)
 		auth.PATCH("/user", handlers.EditUserDetails)
+		auth.PATCH("/user/password", handlers.ChangePassword)
+		auth.POST("/user/email", handlers.RequestEmailChange)
+		auth.GET("/user/export", handlers.ExportUserData)
+		auth.DELETE("/user", handlers.DeleteAccount)
 		auth.POST("/requested-items", handlers.CreateRequestedItem)
-		auth.POST("/requested-items/fulfill", handlers.FulfillRequestedItem)
+		auth.POST("/requested-items/:id/offers", handlers.CreateOffer)
+		auth.GET("/requested-items/:id/offers", handlers.ListOffers)
+		auth.GET("/my-offers", handlers.GetMyOffers)
+		auth.PATCH("/offers/:id/accept", handlers.AcceptOffer)
+		auth.PATCH("/offers/:id/decline", handlers.DeclineOffer)
+		auth.PATCH("/offers/:id/withdraw", handlers.WithdrawOffer)
 		auth.GET("/my-requested-items", handlers.GetMyRequestedItems)
 		auth.PATCH("/requested-items/:id/close", handlers.CloseRequestedItem)
+		auth.POST("/requested-items/:id/report", handlers.ReportRequestedItem)
+		auth.POST("/requested-items/:id/renew", handlers.RenewRequestedItem)
+		auth.GET("/requested-items/:id/suggestions", handlers.GetRequestedItemSuggestions)
+		auth.POST("/users/:id/report", handlers.ReportUser)
 
 		// Service provider routes
 		auth.POST("/services", handlers.CreateService)
 		auth.GET("/my-services", handlers.GetMyServices)
+		auth.PATCH("/services/:id", handlers.UpdateService)
+		auth.PATCH("/services/:id/pause", handlers.PauseService)
+		auth.PATCH("/services/:id/resume", handlers.ResumeService)
+		auth.DELETE("/services/:id", handlers.DeleteService)
+		auth.PUT("/services/:id/resubmit", handlers.ResubmitService)
+		auth.POST("/services/:id/report", handlers.ReportService)
+		auth.POST("/services/:id/appeal", handlers.AppealService)
+		auth.POST("/services/:id/renew", handlers.RenewService)
+
+		// Booking routes
+		auth.POST("/services/:id/slots", handlers.CreateSlot)
+		auth.DELETE("/slots/:id", handlers.RemoveSlot)
+		auth.POST("/slots/:id/book", handlers.BookSlot)
+		auth.GET("/my-bookings", handlers.GetMyBookings)
+		auth.PATCH("/bookings/:id/confirm", handlers.ConfirmBooking)
+		auth.PATCH("/bookings/:id/cancel", handlers.CancelBooking)
+		auth.PATCH("/bookings/:id/complete", handlers.CompleteBooking)
+
+		// Calendar routes
+		auth.GET("/calendar", handlers.GetCalendarFeed)
+		auth.POST("/calendar/reset", handlers.ResetCalendarFeed)
+		auth.GET("/calendar/events/:type/:id", handlers.DownloadCalendarEvent)
 
 		// Service requester routes
 		auth.POST("/service-requests", handlers.CreateServiceRequest)
 		auth.GET("/my-service-requests", handlers.GetMyServiceRequests)
 		auth.PATCH("/service-requests/:id/complete", handlers.CompleteServiceRequest)
 		auth.PATCH("/service-requests/:id/cancel", handlers.CancelServiceRequest)
+		auth.POST("/service-requests/:id/report", handlers.ReportServiceRequest)
+		auth.POST("/service-requests/:id/renew", handlers.RenewServiceRequest)
 
-		// Service fulfillment route
-		auth.PATCH("/service-requests/:id/accept", handlers.AcceptServiceRequest)
+		// Service fulfillment routes
+		auth.POST("/service-requests/:id/proposals", handlers.CreateProposal)
+		auth.GET("/service-requests/:id/proposals", handlers.ListProposals)
+		auth.GET("/my-proposals", handlers.GetMyProposals)
+		auth.PATCH("/proposals/:id/accept", handlers.AcceptProposal)
+		auth.PATCH("/proposals/:id/decline", handlers.DeclineProposal)
+		auth.PATCH("/proposals/:id/withdraw", handlers.WithdrawProposal)
 		auth.GET("/service-requests/taken", handlers.GetServiceRequestsITook)
 
 		// Favorites routes
@@ -73,18 +125,57 @@ func SetupRouter() *gin.Engine {
 		auth.GET("/check-contact", handlers.CheckContactDetails)
 	}
 
-	// Admin routes
+	// Admin routes, each declaring the permission it requires
 	admin := auth.Group("/admin")
-	admin.Use(middleware.Admin())
+	admin.Use(middleware.Staff())
 	{
-		admin.GET("/items", handlers.ListPendingItems)
-		admin.PATCH("/items/:id/approve", handlers.ApproveItem)
-		admin.PATCH("/items/:id/reject", handlers.RejectItem)
-		admin.POST("/hostels", handlers.CreateHostel)
-
-		admin.GET("/services", handlers.ListPendingServices)
-		admin.PATCH("/services/:id/approve", handlers.ApproveService)
-		admin.PATCH("/services/:id/reject", handlers.RejectService)
+		admin.GET("/items", middleware.RequirePermission(rbac.ViewModerationQueue), handlers.ListPendingItems)
+		admin.PATCH("/items/:id/approve", middleware.RequirePermission(rbac.ModerateListings), handlers.ApproveItem)
+		admin.PATCH("/items/:id/reject", middleware.RequirePermission(rbac.ModerateListings), handlers.RejectItem)
+		admin.POST("/hostels", middleware.RequirePermission(rbac.ManageHostels), handlers.CreateHostel)
+
+		admin.GET("/services", middleware.RequirePermission(rbac.ViewModerationQueue), handlers.ListPendingServices)
+		admin.PATCH("/services/:id/approve", middleware.RequirePermission(rbac.ModerateListings), handlers.ApproveService)
+		admin.PATCH("/services/:id/reject", middleware.RequirePermission(rbac.ModerateListings), handlers.RejectService)
+
+		admin.GET("/offers", middleware.RequirePermission(rbac.ViewModerationQueue), handlers.ListOffersForReview)
+		admin.PATCH("/offers/:id/approve", middleware.RequirePermission(rbac.ModerateListings), handlers.ApproveOffer)
+		admin.PATCH("/offers/:id/reject", middleware.RequirePermission(rbac.ModerateListings), handlers.RejectOffer)
+
+		admin.GET("/requested-items", middleware.RequirePermission(rbac.ViewModerationQueue), handlers.ListRequestedItemsForReview)
+		admin.PATCH("/requested-items/:id/approve", middleware.RequirePermission(rbac.ModerateListings), handlers.ApproveRequestedItem)
+		admin.PATCH("/requested-items/:id/reject", middleware.RequirePermission(rbac.ModerateListings), handlers.RejectRequestedItem)
+
+		admin.GET("/service-requests", middleware.RequirePermission(rbac.ViewModerationQueue), handlers.ListServiceRequestsForReview)
+		admin.PATCH("/service-requests/:id/approve", middleware.RequirePermission(rbac.ModerateListings), handlers.ApproveServiceRequest)
+		admin.PATCH("/service-requests/:id/reject", middleware.RequirePermission(rbac.ModerateListings), handlers.RejectServiceRequest)
+
+		admin.GET("/roles", middleware.RequirePermission(rbac.ManageRoles), handlers.ListRoles)
+		admin.PATCH("/users/:id/role", middleware.RequirePermission(rbac.ManageRoles), handlers.AssignRole)
+
+		admin.GET("/users", middleware.RequirePermission(rbac.ViewUsers), handlers.SearchUsers)
+		admin.GET("/users/:id", middleware.RequirePermission(rbac.ViewUsers), handlers.GetUserForAdmin)
+		admin.PATCH("/users/:id/suspend", middleware.RequirePermission(rbac.ManageUsers), handlers.SuspendUser)
+		admin.PATCH("/users/:id/ban", middleware.RequirePermission(rbac.ManageUsers), handlers.BanUser)
+		admin.PATCH("/users/:id/reinstate", middleware.RequirePermission(rbac.ManageUsers), handlers.ReinstateUser)
+
+		admin.GET("/appeals", middleware.RequirePermission(rbac.ViewModerationQueue), handlers.ListAppeals)
+		admin.PATCH("/appeals/:id/grant", middleware.RequirePermission(rbac.ModerateListings), handlers.GrantAppeal)
+		admin.PATCH("/appeals/:id/deny", middleware.RequirePermission(rbac.ModerateListings), handlers.DenyAppeal)
+
+		admin.GET("/reports", middleware.RequirePermission(rbac.ViewReports), handlers.ListReports)
+		admin.GET("/reports/queue", middleware.RequirePermission(rbac.ViewReports), handlers.GetReportQueue)
+		admin.PATCH("/reports/:id/resolve", middleware.RequirePermission(rbac.ResolveReports), handlers.ResolveReport)
+
+		admin.GET("/audit-logs", middleware.RequirePermission(rbac.ViewAuditLog), handlers.ListAuditLogs)
+
+		admin.GET("/jobs", middleware.RequirePermission(rbac.ViewJobs), handlers.ListJobs)
+
+		admin.GET("/moderation/rules", middleware.RequirePermission(rbac.ViewModerationQueue), handlers.GetModerationRules)
+		admin.PUT("/moderation/rules", middleware.RequirePermission(rbac.ManageModerationRules), handlers.UpdateModerationRules)
+		admin.POST("/moderation/rules/test", middleware.RequirePermission(rbac.ViewModerationQueue), handlers.TestModerationRules)
+		admin.GET("/moderation/results", middleware.RequirePermission(rbac.ViewModerationQueue), handlers.ListModerationResults)
+		admin.GET("/moderation/stats", middleware.RequirePermission(rbac.ViewModerationQueue), handlers.GetModerationStats)
 	}
 
 	return r
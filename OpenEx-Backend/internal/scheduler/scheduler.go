@@ -0,0 +1,314 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Job is a task that runs periodically in the background
+type Job struct {
+	Name       string
+	Interval   time.Duration
+	Timeout    time.Duration // Optional limit for a single run; zero means no limit
+	RunOnStart bool          // Run once right away instead of waiting for the first interval
+	Exclusive  bool          // Run on only one instance at a time, using the scheduler's Locker
+	Run        func(ctx context.Context) error
+}
+
+// Locker provides cluster-wide locks for exclusive jobs
+type Locker interface {
+	// TryLock takes the named lock without waiting; ok is false if another instance holds it
+	TryLock(ctx context.Context, name string) (unlock func(), ok bool, err error)
+}
+
+// Stats are the timing metrics collected for a job
+type Stats struct {
+	Name          string        `json:"name"`
+	Interval      time.Duration `json:"interval"`
+	Running       bool          `json:"running"`
+	Runs          int64         `json:"runs"`
+	Failures      int64         `json:"failures"`
+	Panics        int64         `json:"panics"`
+	Skipped       int64         `json:"skipped"`     // Ticks dropped because the previous run was still going
+	LockMissed    int64         `json:"lock_missed"` // Ticks dropped because another instance held the lock
+	LastStart     *time.Time    `json:"last_start"`
+	LastDuration  time.Duration `json:"last_duration"`
+	MaxDuration   time.Duration `json:"max_duration"`
+	TotalDuration time.Duration `json:"total_duration"`
+	LastError     string        `json:"last_error"`
+}
+
+// entry is a registered job together with its state
+type entry struct {
+	job     Job
+	mu      sync.Mutex
+	running bool
+	stats   Stats
+}
+
+// Scheduler runs registered jobs on their intervals until it is stopped
+type Scheduler struct {
+	mu      sync.Mutex
+	jobs    map[string]*entry
+	cancel  context.CancelFunc
+	ctx     context.Context
+	wg      sync.WaitGroup
+	started bool
+	locker  Locker
+}
+
+// Default is the scheduler used by the application
+var Default = New()
+
+// New creates an empty scheduler
+func New() *Scheduler {
+	return &Scheduler{jobs: map[string]*entry{}}
+}
+
+// SetLocker sets the lock used by exclusive jobs. Without one, exclusive jobs run on
+// every instance.
+func (s *Scheduler) SetLocker(locker Locker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.locker = locker
+}
+
+// Register adds a job. Jobs registered after Start begin running immediately.
+func (s *Scheduler) Register(job Job) error {
+	if job.Name == "" || job.Run == nil || job.Interval <= 0 {
+		return errors.New("a job needs a name, a run function and a positive interval")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.jobs[job.Name]; exists {
+		return fmt.Errorf("job %q is already registered", job.Name)
+	}
+
+	e := &entry{job: job, stats: Stats{Name: job.Name, Interval: job.Interval}}
+	s.jobs[job.Name] = e
+	if s.started {
+		s.startLoop(e)
+	}
+	return nil
+}
+
+// Start runs every registered job until ctx is cancelled or Stop is called
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	s.started = true
+
+	for _, e := range s.jobs {
+		s.startLoop(e)
+	}
+	log.Printf("Scheduler started with %d jobs", len(s.jobs))
+}
+
+// Go runs a long-lived background task, e.g. a queue consumer, that is stopped together
+// with the scheduler. Panics are logged and end the task.
+func (s *Scheduler) Go(name string, task func(ctx context.Context)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.started {
+		log.Printf("Scheduler not started, not running task %s", name)
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("Background task %s panicked: %v\n%s", name, r, debug.Stack())
+			}
+		}()
+		task(s.ctx)
+	}()
+}
+
+// Stop cancels all jobs and waits for running ones to return, or for ctx to expire
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.started {
+		s.mu.Unlock()
+		return nil
+	}
+	s.cancel()
+	s.started = false
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		log.Println("Scheduler stopped")
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for jobs to finish: %w", ctx.Err())
+	}
+}
+
+// Stats returns the metrics of every job, sorted by name
+func (s *Scheduler) Stats() []Stats {
+	s.mu.Lock()
+	entries := make([]*entry, 0, len(s.jobs))
+	for _, e := range s.jobs {
+		entries = append(entries, e)
+	}
+	s.mu.Unlock()
+
+	stats := make([]Stats, 0, len(entries))
+	for _, e := range entries {
+		e.mu.Lock()
+		current := e.stats
+		current.Running = e.running
+		e.mu.Unlock()
+		stats = append(stats, current)
+	}
+
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats
+}
+
+// startLoop starts the ticker goroutine of a job. The caller holds s.mu.
+func (s *Scheduler) startLoop(e *entry) {
+	ctx := s.ctx
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(e.job.Interval)
+		defer ticker.Stop()
+
+		if e.job.RunOnStart {
+			s.trigger(ctx, e)
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.trigger(ctx, e)
+			}
+		}
+	}()
+}
+
+// trigger starts a run of the job unless the previous one is still going
+func (s *Scheduler) trigger(ctx context.Context, e *entry) {
+	e.mu.Lock()
+	if e.running {
+		e.stats.Skipped++
+		e.mu.Unlock()
+		log.Printf("Job %s is still running, skipping this run", e.job.Name)
+		return
+	}
+	e.running = true
+	e.mu.Unlock()
+
+	s.mu.Lock()
+	locker := s.locker
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		if e.job.Exclusive && locker != nil {
+			unlock, ok, err := locker.TryLock(ctx, "job:"+e.job.Name)
+			if err != nil || !ok {
+				e.mu.Lock()
+				e.running = false
+				if err != nil {
+					e.stats.Failures++
+					e.stats.LastError = fmt.Sprintf("taking lock: %v", err)
+				} else {
+					e.stats.LockMissed++
+				}
+				e.mu.Unlock()
+
+				if err != nil {
+					log.Printf("Job %s could not take its lock: %v", e.job.Name, err)
+				}
+				return
+			}
+			defer unlock()
+		}
+
+		s.run(ctx, e)
+	}()
+}
+
+// run executes a single run of a job, recovering from panics and recording its metrics
+func (s *Scheduler) run(parent context.Context, e *entry) {
+	ctx := parent
+	if e.job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(parent, e.job.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	var err error
+	panicked := false
+
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				panicked = true
+				err = fmt.Errorf("panic: %v", r)
+				log.Printf("Job %s panicked: %v\n%s", e.job.Name, r, debug.Stack())
+			}
+		}()
+		err = e.job.Run(ctx)
+	}()
+
+	duration := time.Since(start)
+
+	// A job interrupted by shutdown has not failed
+	if !panicked && parent.Err() != nil && errors.Is(err, context.Canceled) {
+		log.Printf("Job %s stopped after %v", e.job.Name, duration)
+		err = nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.running = false
+	e.stats.Runs++
+	e.stats.LastStart = &start
+	e.stats.LastDuration = duration
+	e.stats.TotalDuration += duration
+	if duration > e.stats.MaxDuration {
+		e.stats.MaxDuration = duration
+	}
+	e.stats.LastError = ""
+	if panicked {
+		e.stats.Panics++
+	}
+	if err != nil {
+		e.stats.Failures++
+		e.stats.LastError = err.Error()
+		if !panicked {
+			log.Printf("Job %s failed after %v: %v", e.job.Name, duration, err)
+		}
+	}
+}
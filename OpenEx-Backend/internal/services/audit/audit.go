@@ -0,0 +1,56 @@
+package audit
+
+import (
+	"log"
+
+	"OpenEx-Backend/internal/database"
+	"OpenEx-Backend/internal/models"
+)
+
+// Actor identifies who performed an audited action
+type Actor struct {
+	Type string
+	ID   *uint
+	Name string
+}
+
+// UserActor returns the actor for a staff member acting through the API
+func UserActor(user models.User) Actor {
+	id := user.ID
+	return Actor{Type: "user", ID: &id, Name: user.Name}
+}
+
+// WorkerActor returns the actor for a background worker
+func WorkerActor(name string) Actor {
+	return Actor{Type: "worker", Name: name}
+}
+
+// Entry describes a single state change to record
+type Entry struct {
+	Action     string
+	TargetType string
+	TargetID   uint
+	FromStatus string
+	ToStatus   string
+	Reason     string
+}
+
+// Record appends an entry to the audit log. Failures are logged rather than returned
+// so that auditing never blocks the action being audited.
+func Record(actor Actor, entry Entry) {
+	record := models.AuditLog{
+		ActorType:  actor.Type,
+		ActorID:    actor.ID,
+		ActorName:  actor.Name,
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		FromStatus: entry.FromStatus,
+		ToStatus:   entry.ToStatus,
+		Reason:     entry.Reason,
+	}
+
+	if err := database.DB.Create(&record).Error; err != nil {
+		log.Printf("Error writing audit log for %s on %s #%d: %v", entry.Action, entry.TargetType, entry.TargetID, err)
+	}
+}
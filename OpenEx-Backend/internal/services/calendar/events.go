@@ -0,0 +1,234 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"OpenEx-Backend/internal/database"
+	"OpenEx-Backend/internal/models"
+	"OpenEx-Backend/internal/services/expiry"
+
+	"gorm.io/gorm"
+)
+
+// Event types that are not listing expiry dates. Expiry events use the expiry kind's target
+// type followed by "_expiry", e.g. "item_expiry".
+const (
+	TypeBooking            = "booking"
+	TypeServiceRequest     = "service_request"
+	TypeTransactionRequest = "transaction_request"
+)
+
+// ErrNotFound is returned when the user has no such event
+var ErrNotFound = errors.New("event not found")
+
+// scope narrows a source's query, e.g. to a single record
+type scope func(*gorm.DB) *gorm.DB
+
+// source loads the events of one type for a user
+type source func(user models.User, narrow scope) ([]Event, error)
+
+// sources returns the loader of every event type
+func sources() map[string]source {
+	all := map[string]source{
+		TypeBooking:            bookingEvents,
+		TypeServiceRequest:     serviceRequestEvents,
+		TypeTransactionRequest: transactionRequestEvents,
+	}
+	for _, kind := range expiry.Kinds {
+		all[kind.TargetType+"_expiry"] = expiryEvents(kind)
+	}
+	return all
+}
+
+// uid builds the stable identifier calendar apps use to update an event in place
+func uid(eventType string, id uint) string {
+	return fmt.Sprintf("%s-%d@openex", eventType, id)
+}
+
+// Upcoming returns the user's upcoming commitments: active bookings, service requests in
+// progress, transaction requests awaiting handoff and expiry dates of their live records
+func Upcoming(user models.User) ([]Event, error) {
+	var events []Event
+	for eventType, load := range sources() {
+		loaded, err := load(user, func(db *gorm.DB) *gorm.DB { return db })
+		if err != nil {
+			return nil, fmt.Errorf("loading %s events: %w", eventType, err)
+		}
+		events = append(events, loaded...)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Start.Before(events[j].Start) })
+	return events, nil
+}
+
+// Find returns a single upcoming event of the user by type and record ID
+func Find(user models.User, eventType string, id uint) (Event, error) {
+	load, ok := sources()[eventType]
+	if !ok {
+		return Event{}, ErrNotFound
+	}
+
+	events, err := load(user, func(db *gorm.DB) *gorm.DB { return db.Where("id = ?", id) })
+	if err != nil {
+		return Event{}, err
+	}
+	if len(events) == 0 {
+		return Event{}, ErrNotFound
+	}
+	return events[0], nil
+}
+
+// bookingEvents returns the requested and confirmed bookings the user made or received that
+// have not ended yet
+func bookingEvents(user models.User, narrow scope) ([]Event, error) {
+	var bookings []models.Booking
+	if err := database.DB.Scopes(narrow).Preload("Service").Preload("Requester").Preload("Provider").
+		Where("(requester_id = ? OR provider_id = ?) AND status IN ? AND ends_at > ?",
+			user.ID, user.ID, []string{"requested", "confirmed"}, time.Now()).
+		Find(&bookings).Error; err != nil {
+		return nil, err
+	}
+
+	events := make([]Event, 0, len(bookings))
+	for _, booking := range bookings {
+		other := booking.Provider.Name
+		if booking.ProviderID == user.ID {
+			other = booking.Requester.Name
+		}
+
+		summary := fmt.Sprintf("%s with %s", booking.Service.Title, other)
+		if booking.Status == "requested" {
+			summary += " (awaiting confirmation)"
+		}
+
+		events = append(events, Event{
+			UID:         uid(TypeBooking, booking.ID),
+			Summary:     summary,
+			Description: booking.Note,
+			Start:       booking.StartsAt,
+			End:         booking.EndsAt,
+			Updated:     booking.UpdatedAt,
+		})
+	}
+	return events, nil
+}
+
+// serviceRequestEvents returns the in-progress service requests the user asked for or works
+// on, running from acceptance to the agreed completion date
+func serviceRequestEvents(user models.User, narrow scope) ([]Event, error) {
+	var requests []models.ServiceRequest
+	if err := database.DB.Scopes(narrow).Preload("Requester").Preload("Provider").
+		Where("(requester_id = ? OR provider_id = ?) AND status = ? AND accepted_at IS NOT NULL",
+			user.ID, user.ID, "in-progress").
+		Find(&requests).Error; err != nil {
+		return nil, err
+	}
+
+	events := make([]Event, 0, len(requests))
+	for _, request := range requests {
+		summary := fmt.Sprintf("%s (by %s)", request.Title, request.Provider.Name)
+		if request.ProviderID != nil && *request.ProviderID == user.ID {
+			summary = fmt.Sprintf("%s for %s", request.Title, request.Requester.Name)
+		}
+
+		event := Event{
+			UID:         uid(TypeServiceRequest, request.ID),
+			Summary:     summary,
+			Description: request.Description,
+			Updated:     request.UpdatedAt,
+		}
+		if request.DueAt != nil && request.DueAt.After(*request.AcceptedAt) {
+			event.Start, event.End = *request.AcceptedAt, *request.DueAt
+		} else {
+			// No agreed completion date, so mark the day the work started
+			event.AllDay = true
+			event.Start = DayStart(*request.AcceptedAt)
+			event.End = event.Start.AddDate(0, 0, 1)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// transactionRequestEvents returns the pending and approved transaction requests of the user.
+// They have no handoff date, so each runs as an all-day event from the day it was made or
+// approved through today, until the handoff is done.
+func transactionRequestEvents(user models.User, narrow scope) ([]Event, error) {
+	var requests []models.TransactionRequest
+	if err := database.DB.Scopes(narrow).Preload("Item").Preload("Buyer").Preload("Seller").
+		Where("(buyer_id = ? OR seller_id = ?) AND status IN ?", user.ID, user.ID, []string{"pending", "approved"}).
+		Find(&requests).Error; err != nil {
+		return nil, err
+	}
+
+	tomorrow := DayStart(time.Now()).AddDate(0, 0, 1)
+	events := make([]Event, 0, len(requests))
+	for _, request := range requests {
+		selling := request.SellerID == user.ID
+
+		var summary string
+		since := request.CreatedAt
+		switch {
+		case request.Status == "pending" && selling:
+			summary = fmt.Sprintf("Answer %s's request for %s", request.Buyer.Name, request.Item.Title)
+		case request.Status == "pending":
+			summary = fmt.Sprintf("Waiting for %s to answer about %s", request.Seller.Name, request.Item.Title)
+		case selling:
+			summary = fmt.Sprintf("Hand over %s to %s", request.Item.Title, request.Buyer.Name)
+			since = request.UpdatedAt
+		default:
+			summary = fmt.Sprintf("Collect %s from %s", request.Item.Title, request.Seller.Name)
+			since = request.UpdatedAt
+		}
+
+		events = append(events, Event{
+			UID:         uid(TypeTransactionRequest, request.ID),
+			Summary:     summary,
+			Description: fmt.Sprintf("%d × %s (%s request)", request.Quantity, request.Item.Title, request.Type),
+			Start:       DayStart(since),
+			End:         tomorrow,
+			AllDay:      true,
+			Updated:     request.UpdatedAt,
+		})
+	}
+	return events, nil
+}
+
+// expiringRecord is a live record of an expiry kind
+type expiringRecord struct {
+	ID        uint
+	Title     string
+	ExpiresAt time.Time
+	UpdatedAt time.Time
+}
+
+// expiryEvents returns a loader of the expiry dates of the user's live records of a kind
+func expiryEvents(kind expiry.Kind) source {
+	return func(user models.User, narrow scope) ([]Event, error) {
+		var records []expiringRecord
+		if err := database.DB.Table(kind.Table).Scopes(narrow).
+			Select("id, title, expires_at, updated_at").
+			Where(kind.OwnerColumn+" = ? AND status = ? AND expires_at > ?", user.ID, kind.ActiveStatus, time.Now()).
+			Scan(&records).Error; err != nil {
+			return nil, err
+		}
+
+		events := make([]Event, 0, len(records))
+		for _, record := range records {
+			start := DayStart(record.ExpiresAt)
+			events = append(events, Event{
+				UID:         uid(kind.TargetType+"_expiry", record.ID),
+				Summary:     fmt.Sprintf("Your %s \"%s\" expires", kind.Label, record.Title),
+				Description: fmt.Sprintf("Renew it before %s to keep it live.", record.ExpiresAt.Local().Format("January 2, 2006 at 3:04 PM")),
+				Start:       start,
+				End:         start.AddDate(0, 0, 1),
+				AllDay:      true,
+				Updated:     record.UpdatedAt,
+			})
+		}
+		return events, nil
+	}
+}
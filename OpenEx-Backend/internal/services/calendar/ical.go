@@ -0,0 +1,111 @@
+package calendar
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Formats of iCalendar date-time and date values
+const (
+	dateTimeFormat = "20060102T150405Z"
+	dateFormat     = "20060102"
+)
+
+// maxLineLength is the longest a content line may be before it is folded, in octets
+const maxLineLength = 75
+
+// Event is one entry of an iCalendar document
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool // Start and End are dates, End exclusive
+	Updated     time.Time
+}
+
+// Write renders events as an iCalendar (RFC 5545) document named name
+func Write(w io.Writer, name string, events []Event) error {
+	out := bufio.NewWriter(w)
+	line := func(content string) {
+		out.WriteString(fold(content))
+		out.WriteString("\r\n")
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//OpenEx//Calendar//EN")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	line("X-WR-CALNAME:" + escape(name))
+
+	stamp := time.Now().UTC().Format(dateTimeFormat)
+	for _, event := range events {
+		line("BEGIN:VEVENT")
+		line("UID:" + event.UID)
+		line("DTSTAMP:" + stamp)
+		if event.AllDay {
+			line("DTSTART;VALUE=DATE:" + event.Start.Local().Format(dateFormat))
+			line("DTEND;VALUE=DATE:" + event.End.Local().Format(dateFormat))
+		} else {
+			line("DTSTART:" + event.Start.UTC().Format(dateTimeFormat))
+			line("DTEND:" + event.End.UTC().Format(dateTimeFormat))
+		}
+		line("SUMMARY:" + escape(event.Summary))
+		if event.Description != "" {
+			line("DESCRIPTION:" + escape(event.Description))
+		}
+		if !event.Updated.IsZero() {
+			line("LAST-MODIFIED:" + event.Updated.UTC().Format(dateTimeFormat))
+		}
+		line("END:VEVENT")
+	}
+
+	line("END:VCALENDAR")
+	return out.Flush()
+}
+
+// escape escapes a text value
+func escape(text string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", "",
+	).Replace(text)
+}
+
+// fold splits a content line into lines of at most 75 octets, continuing each with a space.
+// Multi-byte characters are never split.
+func fold(content string) string {
+	if len(content) <= maxLineLength {
+		return content
+	}
+
+	var folded strings.Builder
+	limit := maxLineLength
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		folded.WriteString(content[:cut])
+		folded.WriteString("\r\n ")
+		content = content[cut:]
+		limit = maxLineLength - 1 // The leading space counts towards the line
+	}
+	folded.WriteString(content)
+	return folded.String()
+}
+
+// DayStart returns midnight at the start of t's day, for all-day events
+func DayStart(t time.Time) time.Time {
+	t = t.Local()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
@@ -0,0 +1,142 @@
+package calendar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"plain text", "plain text"},
+		{"Maths, physics", `Maths\, physics`},
+		{"Room 4; bring notes", `Room 4\; bring notes`},
+		{`C:\notes`, `C:\\notes`},
+		{"first\nsecond", `first\nsecond`},
+		{"first\r\nsecond", `first\nsecond`},
+		{"stray\rreturn", "strayreturn"},
+		{`a,b;c\d` + "\n", `a\,b\;c\\d\n`},
+	}
+
+	for _, tt := range tests {
+		if got := escape(tt.text); got != tt.want {
+			t.Errorf("escape(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"short", "SUMMARY:Tutoring", "SUMMARY:Tutoring"},
+		{"exactly 75 octets", strings.Repeat("a", 75), strings.Repeat("a", 75)},
+		{"76 octets", strings.Repeat("a", 76), strings.Repeat("a", 75) + "\r\n a"},
+		{
+			"continuation lines hold 74 octets after the space",
+			strings.Repeat("a", 75+74+1),
+			strings.Repeat("a", 75) + "\r\n " + strings.Repeat("a", 74) + "\r\n a",
+		},
+		{
+			"multi-byte character across the fold point moves to the next line",
+			strings.Repeat("a", 74) + "é",
+			strings.Repeat("a", 74) + "\r\n é",
+		},
+		{
+			"multi-byte character ending at the fold point stays",
+			strings.Repeat("a", 73) + "é" + "b",
+			strings.Repeat("a", 73) + "é\r\n b",
+		},
+		{
+			"three-byte character across the fold point",
+			strings.Repeat("a", 74) + "€",
+			strings.Repeat("a", 74) + "\r\n €",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fold(tt.content)
+			if got != tt.want {
+				t.Errorf("fold() = %q, want %q", got, tt.want)
+			}
+			if unfolded := strings.ReplaceAll(got, "\r\n ", ""); unfolded != tt.content {
+				t.Errorf("unfolding gives %q, want %q", unfolded, tt.content)
+			}
+			for _, line := range strings.Split(got, "\r\n") {
+				if len(line) > maxLineLength || !utf8.ValidString(line) {
+					t.Errorf("line %q is longer than %d octets or splits a character", line, maxLineLength)
+				}
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	events := []Event{
+		{
+			UID:         "booking-12@openex",
+			Summary:     "Maths, physics; chemistry",
+			Description: "Bring your notes.\nRoom 4 " + strings.Repeat("é", 40),
+			Start:       time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC),
+			End:         time.Date(2026, 10, 20, 10, 30, 0, 0, time.UTC),
+			Updated:     time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			UID:     "transaction_request-3@openex",
+			Summary: "Pick up a desk lamp",
+			Start:   time.Date(2026, 10, 21, 0, 0, 0, 0, time.Local),
+			End:     time.Date(2026, 10, 22, 0, 0, 0, 0, time.Local),
+			AllDay:  true,
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, "OpenEx, my calendar", events); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	out := buf.String()
+
+	if !strings.HasPrefix(out, "BEGIN:VCALENDAR\r\n") || !strings.HasSuffix(out, "END:VCALENDAR\r\n") {
+		t.Errorf("Write() output is not wrapped in VCALENDAR:\n%s", out)
+	}
+	if strings.Count(out, "\n") != strings.Count(out, "\r\n") {
+		t.Error("Write() output has lines not ending in CRLF")
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > maxLineLength {
+			t.Errorf("line %q is longer than %d octets", line, maxLineLength)
+		}
+	}
+
+	// Folded lines read back as one
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+	for _, want := range []string{
+		"X-WR-CALNAME:OpenEx\\, my calendar\r\n",
+		"UID:booking-12@openex\r\n",
+		"DTSTART:20261020T090000Z\r\n",
+		"DTEND:20261020T103000Z\r\n",
+		"SUMMARY:Maths\\, physics\\; chemistry\r\n",
+		"DESCRIPTION:Bring your notes.\\nRoom 4 " + strings.Repeat("é", 40) + "\r\n",
+		"LAST-MODIFIED:20261019T080000Z\r\n",
+		"DTSTART;VALUE=DATE:20261021\r\n",
+		"DTEND;VALUE=DATE:20261022\r\n",
+	} {
+		if !strings.Contains(unfolded, want) {
+			t.Errorf("Write() output lacks %q:\n%s", want, out)
+		}
+	}
+	if got := strings.Count(out, "BEGIN:VEVENT\r\n"); got != len(events) {
+		t.Errorf("Write() wrote %d events, want %d", got, len(events))
+	}
+	if strings.Count(out, "LAST-MODIFIED:") != 1 || strings.Count(out, "DESCRIPTION:") != 1 {
+		t.Error("Write() wrote LAST-MODIFIED or DESCRIPTION for an event without one")
+	}
+}
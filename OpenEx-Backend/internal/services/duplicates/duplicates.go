@@ -0,0 +1,139 @@
+package duplicates
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Actions taken when a new listing looks like a duplicate
+const (
+	ActionBlock = "block" // Refuse to create the listing
+	ActionMerge = "merge" // Fold it into the owner's existing listing
+	ActionFlag  = "flag"  // Create it, but send it to human review
+)
+
+// Candidate is an existing listing a new one is compared against
+type Candidate struct {
+	ID        uint
+	UserID    uint
+	Text      string
+	ImageHash string // Hex encoded perceptual hash, empty if unknown
+}
+
+// Match describes why a new listing looks like a duplicate of a candidate
+type Match struct {
+	Candidate
+	Similarity    float64 // Jaccard similarity of the text shingles
+	ImageDistance int     // Hamming distance of the image hashes, -1 if not compared
+}
+
+// Reason returns a human readable explanation of the match
+func (m Match) Reason(kind string) string {
+	if m.ImageDistance >= 0 && m.Similarity < TextThreshold() {
+		return fmt.Sprintf("Possible duplicate of %s #%d (same image)", kind, m.ID)
+	}
+	return fmt.Sprintf("Possible duplicate of %s #%d (%.0f%% similar text)", kind, m.ID, m.Similarity*100)
+}
+
+// Action returns the configured DUPLICATE_ACTION
+func Action() string {
+	switch action := strings.ToLower(strings.TrimSpace(os.Getenv("DUPLICATE_ACTION"))); action {
+	case ActionBlock, ActionMerge:
+		return action
+	}
+	return ActionFlag
+}
+
+// TextThreshold returns the shingle similarity at which two texts count as duplicates
+func TextThreshold() float64 {
+	if value, err := strconv.ParseFloat(os.Getenv("DUPLICATE_TEXT_THRESHOLD"), 64); err == nil && value > 0 && value <= 1 {
+		return value
+	}
+	return 0.8
+}
+
+// ImageDistance returns the largest image hash distance that still counts as the same picture
+func ImageDistance() int {
+	if value, err := strconv.Atoi(os.Getenv("DUPLICATE_IMAGE_DISTANCE")); err == nil && value >= 0 && value <= 64 {
+		return value
+	}
+	return 10
+}
+
+// Window returns how far back listings are compared
+func Window() time.Duration {
+	if value, err := strconv.Atoi(os.Getenv("DUPLICATE_WINDOW_DAYS")); err == nil && value > 0 {
+		return time.Duration(value) * 24 * time.Hour
+	}
+	return 30 * 24 * time.Hour
+}
+
+// Shingles splits text into hashed overlapping word triples. Texts shorter than three
+// words are represented by their words, so short titles still compare sensibly.
+func Shingles(text string) map[uint64]struct{} {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	size := 3
+	if len(words) < size {
+		size = 1
+	}
+
+	shingles := make(map[uint64]struct{})
+	for i := 0; i+size <= len(words); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:i+size], " ")))
+		shingles[h.Sum64()] = struct{}{}
+	}
+	return shingles
+}
+
+// Similarity returns the Jaccard similarity of two shingle sets
+func Similarity(a, b map[uint64]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for shingle := range a {
+		if _, ok := b[shingle]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// FindDuplicate returns the candidate most similar to the new listing, if any is similar
+// enough by text or has practically the same image
+func FindDuplicate(text, imageHash string, candidates []Candidate) *Match {
+	shingles := Shingles(text)
+	textThreshold := TextThreshold()
+	maxDistance := ImageDistance()
+
+	var best *Match
+	for _, candidate := range candidates {
+		match := Match{
+			Candidate:     candidate,
+			Similarity:    Similarity(shingles, Shingles(candidate.Text)),
+			ImageDistance: -1,
+		}
+		if distance, err := HashDistance(imageHash, candidate.ImageHash); err == nil {
+			match.ImageDistance = distance
+		}
+
+		sameImage := match.ImageDistance >= 0 && match.ImageDistance <= maxDistance
+		if match.Similarity < textThreshold && !sameImage {
+			continue
+		}
+		if best == nil || match.Similarity > best.Similarity {
+			best = &match
+		}
+	}
+	return best
+}
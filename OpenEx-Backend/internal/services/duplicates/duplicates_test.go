@@ -0,0 +1,80 @@
+package duplicates
+
+import (
+	"math"
+	"testing"
+)
+
+func TestShingles(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want int
+	}{
+		{"empty", "", 0},
+		{"short text uses single words", "Hello world", 2},
+		{"three word shingles", "a b c d", 2},
+		{"repeated shingles count once", "a b c a b c", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := len(Shingles(tt.text)); got != tt.want {
+				t.Errorf("len(Shingles(%q)) = %d, want %d", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want float64
+	}{
+		{"identical", "calculus notes for first year", "calculus notes for first year", 1},
+		{"case and punctuation are ignored", "Hello, World!", "hello world", 1},
+		{"disjoint", "calculus notes for sale", "used bicycle in good shape", 0},
+		{"one of three shingles shared", "a b c d", "a b c e", 1.0 / 3},
+		{"empty text", "", "anything at all", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Similarity(Shingles(tt.a), Shingles(tt.b))
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Similarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindDuplicate(t *testing.T) {
+	candidates := []Candidate{
+		{ID: 1, Text: "used bicycle in good shape"},
+		{ID: 2, Text: "calculus notes for first year students", ImageHash: "00000000000000ff"},
+	}
+
+	tests := []struct {
+		name      string
+		text      string
+		imageHash string
+		want      uint
+	}{
+		{"same text", "Calculus notes for first year students", "", 2},
+		{"same image, other text", "something else entirely", "00000000000000fe", 2},
+		{"nothing similar", "a lamp and a desk", "ffffffffffffffff", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got uint
+			if match := FindDuplicate(tt.text, tt.imageHash, candidates); match != nil {
+				got = match.ID
+			}
+			if got != tt.want {
+				t.Errorf("FindDuplicate(%q, %q) matched #%d, want #%d", tt.text, tt.imageHash, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package duplicates
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // Register decoders for the formats listings are uploaded in
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"math/bits"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// maxImageBytes caps how much of an image is downloaded for hashing
+const maxImageBytes = 10 << 20
+
+// maxImagePixels caps the decoded size of an image, since a small compressed file can
+// expand to a huge bitmap
+const maxImagePixels = 16_000_000
+
+// ErrImageHostNotAllowed is returned for image URLs outside IMAGE_UPLOAD_HOSTS
+var ErrImageHostNotAllowed = errors.New("image host not allowed")
+
+// sharedAddressSpace is the carrier-grade NAT range, which is as internal as the private ranges
+var _, sharedAddressSpace, _ = net.ParseCIDR("100.64.0.0/10")
+
+// imageDialer refuses to connect to internal addresses, checked after DNS resolution so a
+// public name pointing at an internal address is refused too
+var imageDialer = &net.Dialer{
+	Timeout: 5 * time.Second,
+	Control: func(network, address string, _ syscall.RawConn) error {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return err
+		}
+		if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+			return fmt.Errorf("refusing to fetch image from internal address %s", host)
+		}
+		return nil
+	},
+}
+
+var imageClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext:           imageDialer.DialContext, // No proxy, it would bypass the address check
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: 5 * time.Second,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 3 {
+			return errors.New("too many redirects")
+		}
+		return CheckImageURL(req.URL.String())
+	},
+}
+
+// ImageHosts returns the hosts listing images are uploaded to, from IMAGE_UPLOAD_HOSTS
+// (comma separated). Images elsewhere are never fetched by the server.
+func ImageHosts() []string {
+	var hosts []string
+	for _, host := range strings.Split(os.Getenv("IMAGE_UPLOAD_HOSTS"), ",") {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// CheckImageURL reports whether the server may fetch imageURL: it has to be an http(s) URL
+// on one of the ImageHosts
+func CheckImageURL(imageURL string) error {
+	u, err := url.Parse(imageURL)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.User != nil {
+		return ErrImageHostNotAllowed
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, allowed := range ImageHosts() {
+		if host == allowed {
+			return nil
+		}
+	}
+	return ErrImageHostNotAllowed
+}
+
+// publicIP reports whether ip is a routable public address
+func publicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() && !ip.IsUnspecified() && !sharedAddressSpace.Contains(ip)
+}
+
+// ImageHash downloads an image from an allowed host and returns its perceptual hash, hex
+// encoded. It can take seconds, so callers run it off the request path.
+func ImageHash(imageURL string) (string, error) {
+	if err := CheckImageURL(imageURL); err != nil {
+		return "", err
+	}
+
+	resp, err := imageClient.Get(imageURL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetching image: status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("reading image: %w", err)
+	}
+	if len(data) > maxImageBytes {
+		return "", errors.New("image is larger than 10 MB")
+	}
+
+	// Check the dimensions from the header before allocating the bitmap
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("decoding image: %w", err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxImagePixels {
+		return "", fmt.Errorf("image is %dx%d pixels, too large to hash", config.Width, config.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("decoding image: %w", err)
+	}
+	return fmt.Sprintf("%016x", DifferenceHash(img)), nil
+}
+
+// DifferenceHash computes a 64-bit dHash: the image is shrunk to 9x8 grayscale cells and
+// each bit records whether a cell is darker than its right-hand neighbour. Resizing,
+// recompression and small colour changes leave most bits untouched.
+func DifferenceHash(img image.Image) uint64 {
+	bounds := img.Bounds()
+	var cells [8][9]float64
+
+	for row := 0; row < 8; row++ {
+		y0, y1 := cellRange(bounds.Min.Y, bounds.Dy(), row, 8)
+		for col := 0; col < 9; col++ {
+			x0, x1 := cellRange(bounds.Min.X, bounds.Dx(), col, 9)
+
+			// Sample at most 8x8 pixels per cell to keep large photos cheap
+			stepX, stepY := max(1, (x1-x0)/8), max(1, (y1-y0)/8)
+			var sum float64
+			var count int
+			for y := y0; y < y1; y += stepY {
+				for x := x0; x < x1; x += stepX {
+					r, g, b, _ := img.At(x, y).RGBA()
+					sum += 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+					count++
+				}
+			}
+			if count > 0 {
+				cells[row][col] = sum / float64(count)
+			}
+		}
+	}
+
+	var hash uint64
+	for row := 0; row < 8; row++ {
+		for col := 0; col < 8; col++ {
+			hash <<= 1
+			if cells[row][col] < cells[row][col+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// cellRange returns the pixel range of cell i out of n along an axis, never empty
+func cellRange(start, length, i, n int) (int, int) {
+	from := start + i*length/n
+	to := start + (i+1)*length/n
+	if to <= from {
+		to = from + 1
+	}
+	return from, to
+}
+
+// HashDistance returns the number of differing bits between two hex encoded hashes
+func HashDistance(a, b string) (int, error) {
+	if a == "" || b == "" {
+		return 0, errors.New("missing image hash")
+	}
+
+	x, err := strconv.ParseUint(a, 16, 64)
+	if err != nil {
+		return 0, err
+	}
+	y, err := strconv.ParseUint(b, 16, 64)
+	if err != nil {
+		return 0, err
+	}
+	return bits.OnesCount64(x ^ y), nil
+}
@@ -0,0 +1,102 @@
+package expiry
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Kind describes a table whose records expire when their owner stops renewing them
+type Kind struct {
+	TargetType   string // Audit and report target type
+	Label        string // Human readable name used in emails
+	Table        string
+	OwnerColumn  string
+	ActiveStatus string // Only records in this status expire, and renewing restores it
+	ttlEnv       string
+	defaultDays  int
+}
+
+// Kinds are the expirable records
+var Kinds = []Kind{
+	{"item", "item", "items", "user_id", "approved", "EXPIRY_ITEM_DAYS", 60},
+	{"service", "service", "services", "user_id", "approved", "EXPIRY_SERVICE_DAYS", 90},
+	{"requested_item", "requested item", "requested_items", "buyer_id", "open", "EXPIRY_REQUESTED_ITEM_DAYS", 30},
+	{"service_request", "service request", "service_requests", "requester_id", "open", "EXPIRY_SERVICE_REQUEST_DAYS", 30},
+}
+
+// StatusExpired is the status records are moved to once they expire
+const StatusExpired = "expired"
+
+// Lookup returns the kind with the given target type
+func Lookup(targetType string) (Kind, bool) {
+	for _, kind := range Kinds {
+		if kind.TargetType == targetType {
+			return kind, true
+		}
+	}
+	return Kind{}, false
+}
+
+// TTL returns how long a record stays live after it is published or renewed. Zero means
+// records of this kind never expire.
+func (k Kind) TTL() time.Duration {
+	days := k.defaultDays
+	if value, err := strconv.Atoi(os.Getenv(k.ttlEnv)); err == nil && value >= 0 {
+		days = value
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// ReminderWindow returns how long before expiry the owner is reminded
+func ReminderWindow() time.Duration {
+	if value, err := strconv.Atoi(os.Getenv("EXPIRY_REMINDER_DAYS")); err == nil && value > 0 {
+		return time.Duration(value) * 24 * time.Hour
+	}
+	return 3 * 24 * time.Hour
+}
+
+// renewGrace is how long after expiry a reminder's renew link keeps working
+const renewGrace = 30 * 24 * time.Hour
+
+// RenewToken returns a signed token that renews one record without logging in. It is tied
+// to the record's current expiry, so it stops working once the record has been renewed.
+func RenewToken(targetType string, id uint, expiresAt time.Time) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"purpose":     "renew",
+		"target_type": targetType,
+		"target_id":   id,
+		"expires_at":  expiresAt.Unix(),
+		"exp":         expiresAt.Add(renewGrace).Unix(),
+	})
+	return token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+}
+
+// ParseRenewToken validates a renew token and returns the record it renews and the expiry
+// it was issued for
+func ParseRenewToken(tokenString string) (string, uint, int64, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return []byte(os.Getenv("JWT_SECRET")), nil
+	})
+	if err != nil {
+		return "", 0, 0, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["purpose"] != "renew" {
+		return "", 0, 0, errors.New("not a renew token")
+	}
+	targetType, _ := claims["target_type"].(string)
+	targetID, _ := claims["target_id"].(float64)
+	expiresAt, _ := claims["expires_at"].(float64)
+	if targetType == "" || targetID <= 0 {
+		return "", 0, 0, errors.New("malformed renew token")
+	}
+	return targetType, uint(targetID), int64(expiresAt), nil
+}
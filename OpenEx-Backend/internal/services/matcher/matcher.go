@@ -0,0 +1,185 @@
+package matcher
+
+import (
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"OpenEx-Backend/internal/database"
+	"OpenEx-Backend/internal/models"
+	"OpenEx-Backend/internal/services/notify"
+)
+
+// Weights of the parts of a match score. Keywords matter most; price and location only
+// rank listings that are about the right thing.
+const (
+	keywordWeight  = 0.7
+	priceWeight    = 0.15
+	locationWeight = 0.15
+)
+
+// stopWords are ignored when comparing texts
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "need": true, "needed": true,
+	"want": true, "wanted": true, "looking": true, "any": true, "anyone": true, "have": true,
+	"sell": true, "selling": true, "buy": true, "good": true, "condition": true, "new": true,
+	"used": true, "please": true, "urgent": true, "urgently": true, "who": true, "can": true,
+}
+
+// Match is a listing that fits a requested item
+type Match struct {
+	Item  models.Item
+	Score float64
+}
+
+// MinScore returns the score a listing needs to count as a match
+func MinScore() float64 {
+	if value, err := strconv.ParseFloat(os.Getenv("MATCH_MIN_SCORE"), 64); err == nil && value > 0 && value <= 1 {
+		return value
+	}
+	return 0.6
+}
+
+// Keywords returns the distinct meaningful words of a text, lowercased and with a plural
+// "s" removed so "calculators" matches "calculator"
+func Keywords(text string) map[string]struct{} {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	keywords := make(map[string]struct{})
+	for _, word := range words {
+		if len(word) < 3 || stopWords[word] {
+			continue
+		}
+		if len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") {
+			word = strings.TrimSuffix(word, "s")
+		}
+		keywords[word] = struct{}{}
+	}
+	return keywords
+}
+
+// KeywordSimilarity returns the share of the wanted keywords that the listing mentions
+func KeywordSimilarity(wanted, listing map[string]struct{}) float64 {
+	if len(wanted) == 0 {
+		return 0
+	}
+
+	found := 0
+	for keyword := range wanted {
+		if _, ok := listing[keyword]; ok {
+			found++
+		}
+	}
+	return float64(found) / float64(len(wanted))
+}
+
+// requestKeywords are the keywords a listing is compared against. The title says what is
+// wanted; the description is only used when the title is too vague.
+func requestKeywords(request models.RequestedItem) map[string]struct{} {
+	keywords := Keywords(request.Title)
+	if len(keywords) < 2 {
+		for keyword := range Keywords(request.Description) {
+			keywords[keyword] = struct{}{}
+		}
+	}
+	return keywords
+}
+
+// Score rates how well an item fits a requested item, from 0 to 1. ok is false when the
+// item can't be a match at all: it costs more than the buyer's MaxPrice or shares no
+// keywords. sameHostel is false for items in a nearby hostel.
+func Score(request models.RequestedItem, item models.Item, sameHostel bool) (float64, bool) {
+	if request.MaxPrice > 0 && item.Price > request.MaxPrice {
+		return 0, false
+	}
+
+	similarity := KeywordSimilarity(requestKeywords(request), Keywords(item.Title+" "+item.Description))
+	if similarity == 0 {
+		return 0, false
+	}
+
+	location := 0.5
+	if sameHostel {
+		location = 1
+	}
+	return keywordWeight*similarity + priceWeight + locationWeight*location, true
+}
+
+// NearbyHostelIDs returns the hostel and the other hostels in its zone
+func NearbyHostelIDs(hostelID uint) []uint {
+	ids := []uint{hostelID}
+
+	var hostel models.Hostel
+	if err := database.DB.First(&hostel, hostelID).Error; err != nil || hostel.Zone == "" {
+		return ids
+	}
+
+	var nearby []uint
+	database.DB.Model(&models.Hostel{}).Where("zone = ? AND id <> ?", hostel.Zone, hostelID).Pluck("id", &nearby)
+	return append(ids, nearby...)
+}
+
+// Rank scores items against a requested item and returns the matches, best first
+func Rank(request models.RequestedItem, items []models.Item, limit int) []Match {
+	minScore := MinScore()
+
+	matches := []Match{}
+	for _, item := range items {
+		if item.UserID == request.BuyerID {
+			continue
+		}
+		score, ok := Score(request, item, item.HostelID == request.HostelID)
+		if !ok || score < minScore {
+			continue
+		}
+		matches = append(matches, Match{Item: item, Score: score})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// ItemApproved compares a newly published item with the open requested items in its own and
+// nearby hostels and emails the buyers it matches
+func ItemApproved(item models.Item) {
+	var requests []models.RequestedItem
+	if err := database.DB.Preload("Buyer").
+		Where("status = ? AND hidden_at IS NULL", "open").
+		Where("hostel_id IN ? AND buyer_id <> ?", NearbyHostelIDs(item.HostelID), item.UserID).
+		Where("max_price = 0 OR max_price >= ?", item.Price).
+		Order("created_at DESC").
+		Limit(500).
+		Find(&requests).Error; err != nil {
+		log.Printf("Error finding requests matching item #%d: %v", item.ID, err)
+		return
+	}
+
+	minScore := MinScore()
+	notified := 0
+	for _, request := range requests {
+		buyer := request.Buyer
+		if buyer.AnonymizedAt != nil || buyer.IsBanned() || buyer.IsSuspended() {
+			continue
+		}
+
+		score, ok := Score(request, item, item.HostelID == request.HostelID)
+		if !ok || score < minScore {
+			continue
+		}
+
+		notify.RequestMatched(buyer, request.Title, item.ID, item.Title, item.Price)
+		notified++
+	}
+
+	if notified > 0 {
+		log.Printf("Item #%d matched %d requested items", item.ID, notified)
+	}
+}
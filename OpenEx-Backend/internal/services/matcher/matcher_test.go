@@ -0,0 +1,99 @@
+package matcher
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+
+	"OpenEx-Backend/internal/models"
+)
+
+func TestKeywords(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"empty", "", nil},
+		{"stop words and short words are dropped", "The Calculators, for SALE!", []string{"calculator", "sale"}},
+		{"plural s is removed", "notes books", []string{"book", "note"}},
+		{"double s and short words keep their s", "glass bus", []string{"bus", "glass"}},
+		{"numbers under three digits are dropped", "iPhone 11 with 256 GB", []string{"256", "iphone"}},
+		{"duplicates count once", "lamp lamps LAMP", []string{"lamp"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for keyword := range Keywords(tt.text) {
+				got = append(got, keyword)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Keywords(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScore(t *testing.T) {
+	calculator := models.RequestedItem{Title: "Scientific calculator", MaxPrice: 500}
+
+	tests := []struct {
+		name       string
+		request    models.RequestedItem
+		item       models.Item
+		sameHostel bool
+		want       float64
+		wantOK     bool
+	}{
+		{"full match in the same hostel", calculator, models.Item{Title: "Casio scientific calculators", Price: 400}, true, 1, true},
+		{"full match in a nearby hostel", calculator, models.Item{Title: "Casio scientific calculators", Price: 400}, false, 0.925, true},
+		{"half the keywords", calculator, models.Item{Title: "Graphing calculator", Price: 400}, true, 0.65, true},
+		{"keywords in the item description count", calculator, models.Item{Title: "Casio fx-991", Description: "Scientific calculator", Price: 100}, true, 1, true},
+		{"over the buyer's max price", calculator, models.Item{Title: "Scientific calculator", Price: 501}, true, 0, false},
+		{"no shared keywords", calculator, models.Item{Title: "Desk lamp", Price: 100}, true, 0, false},
+		{"no max price", models.RequestedItem{Title: "Scientific calculator"}, models.Item{Title: "Scientific calculator", Price: 5000}, true, 1, true},
+		{
+			"vague title falls back to the description",
+			models.RequestedItem{Title: "Need it", Description: "Engineering drawing kit"},
+			models.Item{Title: "Drawing kit"},
+			true, 0.7*2/3 + 0.3, true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Score(tt.request, tt.item, tt.sameHostel)
+			if ok != tt.wantOK || math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Score() = %v, %v; want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRank(t *testing.T) {
+	t.Setenv("MATCH_MIN_SCORE", "") // The default minimum of 0.6
+
+	request := models.RequestedItem{BuyerID: 1, HostelID: 1, Title: "Scientific calculator"}
+	items := []models.Item{
+		{ID: 1, UserID: 2, HostelID: 2, Title: "Scientific calculator"},      // 0.925
+		{ID: 2, UserID: 1, HostelID: 1, Title: "Scientific calculator"},      // the buyer's own item
+		{ID: 3, UserID: 3, HostelID: 1, Title: "Scientific calculator"},      // 1
+		{ID: 4, UserID: 4, HostelID: 2, Title: "Graphing calculator"},        // 0.575, below the minimum
+		{ID: 5, UserID: 5, HostelID: 1, Title: "Calculator, barely scuffed"}, // 0.65
+	}
+
+	var got []uint
+	for _, match := range Rank(request, items, 10) {
+		got = append(got, match.Item.ID)
+	}
+	if want := []uint{3, 1, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Rank() = %v, want %v", got, want)
+	}
+
+	if limited := Rank(request, items, 1); len(limited) != 1 || limited[0].Item.ID != 3 {
+		t.Errorf("Rank() with limit 1 = %v, want only item 3", limited)
+	}
+}
@@ -0,0 +1,84 @@
+package moderationlog
+
+import (
+	"log"
+
+	"OpenEx-Backend/internal/database"
+	"OpenEx-Backend/internal/models"
+	"OpenEx-Backend/internal/services/moderator"
+)
+
+// Decision returns the decision name stored for a pipeline outcome
+func Decision(outcome string) string {
+	switch outcome {
+	case moderator.OutcomeReject:
+		return "rejected"
+	case moderator.OutcomeReview:
+		return "needs_review"
+	default:
+		return "approved"
+	}
+}
+
+// providerVerdict returns the verdict name stored for a single provider's answer
+func providerVerdict(verdict moderator.Verdict) string {
+	switch {
+	case !verdict.Approved:
+		return "rejected"
+	case verdict.Flagged:
+		return "flagged"
+	default:
+		return "approved"
+	}
+}
+
+// RecordAutomatic stores the outcome of a moderation pipeline together with every provider's answer
+func RecordAutomatic(targetType string, targetID uint, result moderator.Result) {
+	record := models.ModerationResult{
+		TargetType: targetType,
+		TargetID:   targetID,
+		Decision:   Decision(result.Outcome),
+		Source:     "automatic",
+		Confidence: result.Safety,
+		Reason:     result.Reason,
+	}
+
+	for _, provider := range result.Providers {
+		verdict := providerVerdict(provider.Verdict)
+		if provider.Skipped {
+			verdict = "skipped"
+		} else if provider.Error != "" {
+			verdict = "error"
+		}
+
+		record.Providers = append(record.Providers, models.ModerationProviderScore{
+			Provider: provider.Provider,
+			Verdict:  verdict,
+			Score:    provider.Safety(),
+			Reason:   provider.Reason,
+			Error:    provider.Error,
+		})
+	}
+
+	if err := database.DB.Create(&record).Error; err != nil {
+		log.Printf("Error recording moderation result for %s #%d: %v", targetType, targetID, err)
+	}
+}
+
+// RecordHuman stores a decision made by a staff member
+func RecordHuman(targetType string, targetID uint, actor models.User, decision, reason string) {
+	actorID := actor.ID
+	record := models.ModerationResult{
+		TargetType: targetType,
+		TargetID:   targetID,
+		Decision:   decision,
+		Source:     "human",
+		ActorID:    &actorID,
+		Confidence: 1.0,
+		Reason:     reason,
+	}
+
+	if err := database.DB.Create(&record).Error; err != nil {
+		log.Printf("Error recording moderation result for %s #%d: %v", targetType, targetID, err)
+	}
+}
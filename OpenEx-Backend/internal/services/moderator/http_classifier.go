@@ -0,0 +1,85 @@
+package moderator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+func init() {
+	Register("http", func() (Moderator, error) {
+		return NewHTTPClassifier(os.Getenv("MODERATION_CLASSIFIER_URL"))
+	})
+}
+
+// HTTPClassifier sends content to a classification service over HTTP. It stands in for
+// a locally hosted model: the service receives the content as JSON and answers with a verdict.
+type HTTPClassifier struct {
+	URL    string
+	Client *http.Client
+}
+
+type classifierRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	ImageURL    string `json:"image_url,omitempty"`
+}
+
+type classifierResponse struct {
+	Approved   bool    `json:"approved"`
+	Confidence float64 `json:"confidence"`
+	Reason     string  `json:"reason"`
+}
+
+// NewHTTPClassifier creates a classifier client for the given endpoint
+func NewHTTPClassifier(url string) (*HTTPClassifier, error) {
+	if url == "" {
+		return nil, errors.New("MODERATION_CLASSIFIER_URL is not set")
+	}
+	return &HTTPClassifier{
+		URL:    url,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Name returns the provider name
+func (h *HTTPClassifier) Name() string {
+	return "http"
+}
+
+// Moderate posts the content to the classifier and returns its verdict
+func (h *HTTPClassifier) Moderate(content Content) (Verdict, error) {
+	body, err := json.Marshal(classifierRequest{
+		Title:       content.Title,
+		Description: content.Description,
+		ImageURL:    content.ImageURL,
+	})
+	if err != nil {
+		return Verdict{}, err
+	}
+
+	resp, err := h.Client.Post(h.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return Verdict{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Verdict{}, fmt.Errorf("classifier returned status %d", resp.StatusCode)
+	}
+
+	var result classifierResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return Verdict{}, err
+	}
+
+	return Verdict{
+		Approved:   result.Approved,
+		Confidence: result.Confidence,
+		Reason:     result.Reason,
+	}, nil
+}
@@ -1,55 +1,274 @@
 package moderator
 
 import (
+	"errors"
+	"fmt"
 	"log"
+	"math"
+	"os"
+	"strings"
+	"sync"
 )
 
+// Kinds of content that have their own moderation pipeline
+const (
+	KindItem           = "item"
+	KindService        = "service"
+	KindOffer          = "offer"
+	KindRequestedItem  = "requested_item"
+	KindServiceRequest = "service_request"
+)
+
+// Default provider chains used when no configuration is given
+var defaultProviders = map[string]string{
+	KindItem:           "keyword,sightengine",
+	KindService:        "keyword",
+	KindOffer:          "keyword,sightengine",
+	KindRequestedItem:  "keyword",
+	KindServiceRequest: "keyword",
+}
+
+// ErrNotApplicable is returned by a provider that has nothing to check for the given content,
+// e.g. an image provider asked to moderate content without an image
+var ErrNotApplicable = errors.New("provider not applicable to content")
+
+// Outcomes of evaluating content with a pipeline
+const (
+	OutcomeApprove = "approve"
+	OutcomeReview  = "review" // A human has to decide
+	OutcomeReject  = "reject"
+)
+
+// Content is the user-submitted content to be moderated
+type Content struct {
+	Title       string
+	Description string
+	ImageURL    string
+	Category    string // Item type or service category, used to pick thresholds
+}
+
+// Verdict is a provider's decision about a piece of content
+type Verdict struct {
+	Approved   bool
+	Flagged    bool    // Not rejected, but a human should look at it before it is published
+	Confidence float64 // How sure the provider is of its verdict
+	Reason     string
+}
+
+// Safety converts a verdict into a 0-1 score where higher means safer content
+func (v Verdict) Safety() float64 {
+	if v.Approved {
+		return v.Confidence
+	}
+	return 1 - v.Confidence
+}
+
+// ProviderResult is what a single provider in a chain said about the content
+type ProviderResult struct {
+	Provider string
+	Verdict
+	Skipped bool   // The provider had nothing to check, e.g. no image
+	Error   string // The provider failed
+}
+
+// Result is the combined decision of a chain together with each provider's answer
+type Result struct {
+	Outcome   string
+	Safety    float64 // Lowest safety score of the providers that ran
+	Reason    string
+	Priority  int // Higher values should be reviewed first
+	Providers []ProviderResult
+}
+
+// Moderator is implemented by every content moderation provider
+type Moderator interface {
+	Name() string
+	Moderate(content Content) (Verdict, error)
+}
+
+// Factory creates a configured provider
+type Factory func() (Moderator, error)
+
 var (
-	sightEngine *SightEngine
+	registry = map[string]Factory{}
+
+	pipelinesMu sync.RWMutex
+	pipelines   = map[string]*Chain{}
 )
 
-// Initialize sets up the moderation service
-func Initialize() {
-	// Create the SightEngine client
-	sightEngine = NewSightEngine()
+// Register makes a provider available under a name for use in pipeline configuration
+func Register(name string, factory Factory) {
+	registry[name] = factory
+}
+
+// Chain runs a list of providers in order
+type Chain struct {
+	providers []Moderator
+}
+
+// NewChain creates a chain from already constructed providers
+func NewChain(providers ...Moderator) *Chain {
+	return &Chain{providers: providers}
+}
+
+// BuildChain creates a chain from a comma-separated list of registered provider names
+func BuildChain(names string) (*Chain, error) {
+	var providers []Moderator
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
 
-	// Log the initialization with API credentials (first 4 chars only for security)
-	apiUser := sightEngine.APIUser
-	apiKey := sightEngine.APIKey
-	if len(apiKey) > 4 {
-		apiKey = apiKey[:4] + "****"
+		factory, ok := registry[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown moderation provider %q", name)
+		}
+
+		provider, err := factory()
+		if err != nil {
+			return nil, fmt.Errorf("creating moderation provider %q: %w", name, err)
+		}
+		providers = append(providers, provider)
 	}
+	return NewChain(providers...), nil
+}
 
-	log.Printf("Content moderation service initialized with SightEngine (API User: %s, Key: %s)",
-		apiUser, apiKey)
+// Providers returns the names of the providers in the chain
+func (c *Chain) Providers() []string {
+	names := make([]string, 0, len(c.providers))
+	for _, provider := range c.providers {
+		names = append(names, provider.Name())
+	}
+	return names
 }
 
-// EvaluateContent evaluates both text and image content
-func EvaluateContent(title, description, imageURL string) (bool, float64, string) {
-	// First check text content
-	textApproved, textConfidence, textReason := ModerateText(title, description)
-	if !textApproved {
-		return false, textConfidence, textReason
+// Evaluate runs the providers in order and turns their answers into an outcome using the
+// thresholds. The content's safety is the lowest safety score of any provider that ran:
+// below thresholds.Reject it is rejected, at or above thresholds.Approve it is approved, and
+// anything in between goes to human review. Flags and provider failures always go to review.
+func (c *Chain) Evaluate(content Content, thresholds Thresholds) Result {
+	result := Result{Safety: 1.0}
+	var worst, flag, failure *ProviderResult
+
+	for _, provider := range c.providers {
+		verdict, err := provider.Moderate(content)
+		if errors.Is(err, ErrNotApplicable) {
+			result.Providers = append(result.Providers, ProviderResult{Provider: provider.Name(), Skipped: true})
+			continue
+		}
+		if err != nil {
+			log.Printf("Moderation provider %s error: %v", provider.Name(), err)
+			answer := ProviderResult{Provider: provider.Name(), Error: err.Error()}
+			result.Providers = append(result.Providers, answer)
+			if failure == nil {
+				failure = &answer
+			}
+			continue
+		}
+
+		answer := ProviderResult{Provider: provider.Name(), Verdict: verdict}
+		result.Providers = append(result.Providers, answer)
+
+		if safety := verdict.Safety(); worst == nil || safety < result.Safety {
+			result.Safety = safety
+			worst = &answer
+		}
+		if verdict.Flagged && flag == nil {
+			flag = &answer
+		}
+
+		// No later provider can make clearly unsafe content acceptable
+		if result.Safety < thresholds.Reject {
+			break
+		}
 	}
 
-	// If no image provided, just return text moderation result
-	if imageURL == "" {
-		return true, textConfidence, ""
+	switch {
+	case result.Safety < thresholds.Reject:
+		result.Outcome = OutcomeReject
+		result.Reason = worst.Reason
+	case failure != nil:
+		result.Outcome = OutcomeReview
+		result.Reason = "Moderation provider " + failure.Provider + " failed: " + failure.Error
+		result.Priority = reviewPriority(result.Safety) + 20
+	case flag != nil:
+		result.Outcome = OutcomeReview
+		result.Reason = flag.Reason
+		result.Priority = reviewPriority(result.Safety) + 30
+	case result.Safety < thresholds.Approve:
+		result.Outcome = OutcomeReview
+		result.Reason = fmt.Sprintf("Borderline moderation score %.2f", result.Safety)
+		if worst != nil && worst.Reason != "" {
+			result.Reason += ": " + worst.Reason
+		}
+		result.Priority = reviewPriority(result.Safety)
+	default:
+		result.Outcome = OutcomeApprove
 	}
 
-	// Check image content
-	imageApproved, imageConfidence, imageReason, err := sightEngine.ModerateImageURL(imageURL)
-	if err != nil {
-		log.Printf("Image moderation error: %v", err)
-		// If image moderation fails, approve based on text alone
-		return true, textConfidence, ""
+	return result
+}
+
+// reviewPriority ranks content for the review queue; less safe content comes first
+func reviewPriority(safety float64) int {
+	return int(math.Round((1 - safety) * 100))
+}
+
+// Initialize loads the keyword rules and thresholds and builds the pipeline of each kind from the
+// environment. MODERATION_ITEM_PROVIDERS, MODERATION_SERVICE_PROVIDERS,
+// MODERATION_OFFER_PROVIDERS, MODERATION_REQUESTED_ITEM_PROVIDERS and
+// MODERATION_SERVICE_REQUEST_PROVIDERS take a comma-separated list of provider names (keyword,
+// sightengine, http, noop).
+func Initialize() {
+	if path := RulesFile(); path != "" {
+		if err := LoadRulesFile(path); err != nil {
+			log.Printf("Error loading moderation rules from %s, using defaults: %v", path, err)
+		} else {
+			log.Printf("Loaded moderation rules from %s", path)
+		}
 	}
 
-	// If image is not approved, reject content
-	if !imageApproved {
-		return false, imageConfidence, imageReason
+	if err := loadThresholds(); err != nil {
+		log.Printf("Invalid MODERATION_THRESHOLDS, using defaults: %v", err)
+	}
+
+	for kind, fallback := range defaultProviders {
+		names := os.Getenv("MODERATION_" + strings.ToUpper(kind) + "_PROVIDERS")
+		if names == "" {
+			names = fallback
+		}
+
+		chain, err := BuildChain(names)
+		if err != nil {
+			log.Printf("Invalid %s moderation pipeline %q, falling back to %q: %v", kind, names, fallback, err)
+			if chain, err = BuildChain(fallback); err != nil {
+				log.Printf("Error building default %s moderation pipeline: %v", kind, err)
+				chain = NewChain()
+			}
+		}
+
+		SetPipeline(kind, chain)
+		log.Printf("Content moderation pipeline for %ss: %s", kind, strings.Join(chain.Providers(), " -> "))
 	}
+}
+
+// SetPipeline replaces the chain used for a kind of content, e.g. to inject a fake in tests
+func SetPipeline(kind string, chain *Chain) {
+	pipelinesMu.Lock()
+	defer pipelinesMu.Unlock()
+	pipelines[kind] = chain
+}
+
+// Evaluate moderates content with the pipeline and thresholds configured for its kind
+func Evaluate(kind string, content Content) Result {
+	pipelinesMu.RLock()
+	chain, ok := pipelines[kind]
+	pipelinesMu.RUnlock()
 
-	// Both text and image are clean
-	return true, (textConfidence + imageConfidence) / 2, ""
+	if !ok {
+		log.Printf("No moderation pipeline configured for %s, using keyword check only", kind)
+		chain = NewChain(keywordModerator{})
+	}
+	return chain.Evaluate(content, ThresholdsFor(kind, content.Category))
 }
@@ -0,0 +1,20 @@
+package moderator
+
+func init() {
+	Register("noop", func() (Moderator, error) {
+		return noopModerator{}, nil
+	})
+}
+
+// noopModerator approves everything; useful to disable moderation for a kind of content
+type noopModerator struct{}
+
+// Name returns the provider name
+func (noopModerator) Name() string {
+	return "noop"
+}
+
+// Moderate approves the content unconditionally
+func (noopModerator) Moderate(content Content) (Verdict, error) {
+	return Verdict{Approved: true, Confidence: 1.0}, nil
+}
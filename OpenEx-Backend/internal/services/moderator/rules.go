@@ -0,0 +1,190 @@
+package moderator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Severities a rule category can have
+const (
+	SeverityReject = "reject" // Content is rejected automatically
+	SeverityFlag   = "flag"   // Content is held back for a human to review
+)
+
+// Rule is a word or phrase that belongs to a category
+type Rule struct {
+	Term     string `json:"term"`
+	Category string `json:"category"`
+}
+
+// RuleSet is the full keyword moderation configuration
+type RuleSet struct {
+	Categories map[string]string `json:"categories"` // category name -> severity
+	Rules      []Rule            `json:"rules"`
+	Allow      []string          `json:"allow"` // Phrases that never trigger a rule, e.g. "adult education"
+}
+
+// defaultRules is used when no rules file is configured
+var defaultRules = RuleSet{
+	Categories: map[string]string{
+		"adult":     SeverityReject,
+		"drugs":     SeverityReject,
+		"pharma":    SeverityFlag,
+		"gambling":  SeverityFlag,
+		"violence":  SeverityFlag,
+		"offensive": SeverityFlag,
+	},
+	Rules: []Rule{
+		{Term: "sex", Category: "adult"},
+		{Term: "sexy", Category: "adult"},
+		{Term: "porn", Category: "adult"},
+		{Term: "xxx", Category: "adult"},
+		{Term: "adult", Category: "adult"},
+		{Term: "nude", Category: "adult"},
+		{Term: "naked", Category: "adult"},
+		{Term: "obscene", Category: "adult"},
+		{Term: "gambling", Category: "gambling"},
+		{Term: "casino", Category: "gambling"},
+		{Term: "bet", Category: "gambling"},
+		{Term: "viagra", Category: "pharma"},
+		{Term: "cialis", Category: "pharma"},
+		{Term: "drug", Category: "pharma"},
+		{Term: "cocaine", Category: "drugs"},
+		{Term: "heroin", Category: "drugs"},
+		{Term: "weed", Category: "drugs"},
+		{Term: "kill", Category: "violence"},
+		{Term: "murder", Category: "violence"},
+		{Term: "terrorist", Category: "violence"},
+		{Term: "bomb", Category: "violence"},
+		{Term: "suicide", Category: "violence"},
+		{Term: "offensive", Category: "offensive"},
+	},
+	Allow: []string{
+		"adult education",
+		"young adult",
+		"adult size",
+		"drug store",
+		"bath bomb",
+		"weed killer",
+		"suicide squad",
+		"murder mystery",
+	},
+}
+
+// compiledRule is a rule with its term already normalized into tokens
+type compiledRule struct {
+	Rule
+	tokens   []string
+	severity string
+}
+
+// compiledRuleSet is the form of a RuleSet used for matching
+type compiledRuleSet struct {
+	source RuleSet
+	rules  []compiledRule
+	allow  [][]string
+}
+
+var (
+	rulesMu      sync.RWMutex
+	currentRules = mustCompile(defaultRules)
+)
+
+// compileRules validates a rule set and prepares it for matching
+func compileRules(set RuleSet) (*compiledRuleSet, error) {
+	compiled := &compiledRuleSet{source: set}
+
+	for category, severity := range set.Categories {
+		if severity != SeverityReject && severity != SeverityFlag {
+			return nil, fmt.Errorf("category %q has invalid severity %q", category, severity)
+		}
+	}
+
+	for _, rule := range set.Rules {
+		severity, ok := set.Categories[rule.Category]
+		if !ok {
+			return nil, fmt.Errorf("rule %q uses unknown category %q", rule.Term, rule.Category)
+		}
+
+		tokens := normalizeText(rule.Term)
+		if len(tokens) == 0 {
+			return nil, fmt.Errorf("rule %q has no matchable words", rule.Term)
+		}
+		compiled.rules = append(compiled.rules, compiledRule{Rule: rule, tokens: tokens, severity: severity})
+	}
+
+	for _, phrase := range set.Allow {
+		if tokens := normalizeText(phrase); len(tokens) > 0 {
+			compiled.allow = append(compiled.allow, tokens)
+		}
+	}
+
+	return compiled, nil
+}
+
+// mustCompile compiles a built-in rule set, panicking if it is invalid
+func mustCompile(set RuleSet) *compiledRuleSet {
+	compiled, err := compileRules(set)
+	if err != nil {
+		panic(err)
+	}
+	return compiled
+}
+
+// SetRules validates and activates a new rule set
+func SetRules(set RuleSet) error {
+	compiled, err := compileRules(set)
+	if err != nil {
+		return err
+	}
+
+	rulesMu.Lock()
+	currentRules = compiled
+	rulesMu.Unlock()
+	return nil
+}
+
+// CurrentRules returns the active rule set
+func CurrentRules() RuleSet {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+	return currentRules.source
+}
+
+// activeRules returns the compiled rule set used for matching
+func activeRules() *compiledRuleSet {
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+	return currentRules
+}
+
+// LoadRulesFile reads a JSON rule set from disk and activates it
+func LoadRulesFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var set RuleSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return SetRules(set)
+}
+
+// SaveRulesFile writes the active rule set to disk as JSON
+func SaveRulesFile(path string) error {
+	data, err := json.MarshalIndent(CurrentRules(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// RulesFile returns the configured rules file path, if any
+func RulesFile() string {
+	return strings.TrimSpace(os.Getenv("MODERATION_RULES_FILE"))
+}
@@ -3,12 +3,30 @@ package moderator
 import (
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
 )
 
+func init() {
+	Register("sightengine", func() (Moderator, error) {
+		client := NewSightEngine()
+
+		// Log the API credentials (first 4 chars only for security)
+		apiKey := client.APIKey
+		if len(apiKey) > 4 {
+			apiKey = apiKey[:4] + "****"
+		}
+		log.Printf("SightEngine moderation provider configured (API User: %s, Key: %s, threshold: %.2f)",
+			client.APIUser, apiKey, client.Threshold)
+
+		return client, nil
+	})
+}
+
 // SightEngineResponse represents the response from SightEngine API
 type SightEngineResponse struct {
 	Status  string `json:"status"`
@@ -34,16 +52,50 @@ type SightEngineResponse struct {
 
 // SightEngine is a client for the SightEngine content moderation API
 type SightEngine struct {
-	APIUser string
-	APIKey  string
+	APIUser   string
+	APIKey    string
+	Threshold float64 // Minimum safety score for an image to be approved
 }
 
 // NewSightEngine creates a new SightEngine client
 func NewSightEngine() *SightEngine {
+	// Default to 0.7 (70% safe) unless SIGHTENGINE_THRESHOLD is set
+	threshold := 0.7
+	if value := os.Getenv("SIGHTENGINE_THRESHOLD"); value != "" {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil && parsed > 0 && parsed <= 1 {
+			threshold = parsed
+		}
+	}
+
 	return &SightEngine{
-		APIUser: os.Getenv("SIGHTENGINE_API_USER"),
-		APIKey:  os.Getenv("SIGHTENGINE_API_KEY"),
+		APIUser:   os.Getenv("SIGHTENGINE_API_USER"),
+		APIKey:    os.Getenv("SIGHTENGINE_API_KEY"),
+		Threshold: threshold,
+	}
+}
+
+// Name returns the provider name
+func (s *SightEngine) Name() string {
+	return "sightengine"
+}
+
+// Moderate checks the image of the content; content without an image is not applicable
+func (s *SightEngine) Moderate(content Content) (Verdict, error) {
+	if content.ImageURL == "" {
+		return Verdict{}, ErrNotApplicable
+	}
+
+	approved, safety, reason, err := s.ModerateImageURL(content.ImageURL)
+	if err != nil {
+		return Verdict{}, err
+	}
+
+	// Confidence is how sure we are of the verdict, so a rejection is as certain as the image is unsafe
+	confidence := safety
+	if !approved {
+		confidence = 1 - safety
 	}
+	return Verdict{Approved: approved, Confidence: confidence, Reason: reason}, nil
 }
 
 // ModerateImageURL checks an image URL for inappropriate content
@@ -79,7 +131,8 @@ func (s *SightEngine) ModerateImageURL(imageURL string) (bool, float64, string,
 
 	// Check if there was an API error
 	if result.Request.Events.Error != "" {
-		return false, 0, fmt.Sprintf("API error: %s", result.Request.Events.Error), nil
+		// Treat it as a failure rather than a rejection so the listing goes to human review
+		return false, 0, "API error", fmt.Errorf("sightengine: %s", result.Request.Events.Error)
 	}
 
 	// Calculate overall safety score - lower score means more inappropriate content
@@ -93,8 +146,8 @@ func (s *SightEngine) ModerateImageURL(imageURL string) (bool, float64, string,
 		result.Violence,
 	)
 
-	// Determine if image is safe - threshold of 0.7 (70% safe)
-	isSafe := safety >= 0.7
+	// Determine if image is safe against the configured threshold
+	isSafe := safety >= s.Threshold
 
 	// Generate reason for rejection if not safe
 	var reason string
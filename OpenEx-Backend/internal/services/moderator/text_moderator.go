@@ -2,29 +2,172 @@ package moderator
 
 import (
 	"strings"
+	"unicode"
 )
 
-// List of problematic keywords to check for
-var inappropriateKeywords = []string{
-	"sex", "porn", "xxx", "adult", "nude", "naked",
-	"gambling", "casino", "bet", "betting",
-	"viagra", "cialis", "drug", "cocaine", "heroin", "weed",
-	"kill", "murder", "terrorist", "bomb", "suicide",
-	"obscene", "offensive",
-	// Add more keywords as needed
+func init() {
+	Register("keyword", func() (Moderator, error) {
+		return keywordModerator{}, nil
+	})
 }
 
-// ModerateText checks if text contains inappropriate content
-func ModerateText(title, description string) (bool, float64, string) {
-	combinedText := strings.ToLower(title + " " + description)
+// leetspeak maps look-alike characters back to the letters they stand in for
+var leetspeak = map[rune]rune{
+	'0': 'o',
+	'1': 'i',
+	'3': 'e',
+	'4': 'a',
+	'5': 's',
+	'7': 't',
+	'@': 'a',
+	'$': 's',
+	'!': 'i',
+}
+
+// normalizeText splits text into lowercase, leetspeak-decoded, stemmed words
+func normalizeText(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		_, isLeet := leetspeak[r]
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !isLeet
+	})
 
-	// Check each inappropriate keyword
-	for _, keyword := range inappropriateKeywords {
-		if strings.Contains(combinedText, keyword) {
-			return false, 0.9, "Contains inappropriate keyword: " + keyword
+	tokens := make([]string, 0, len(fields))
+	for _, field := range fields {
+		// Exclamation marks at the edges are punctuation, not an "i"
+		field = strings.Trim(field, "!")
+		if field == "" {
+			continue
+		}
+
+		// Only decode words that contain letters, so prices and model numbers stay as they are
+		if strings.IndexFunc(field, unicode.IsLetter) >= 0 {
+			field = strings.Map(func(r rune) rune {
+				if letter, ok := leetspeak[r]; ok {
+					return letter
+				}
+				return r
+			}, field)
+		}
+
+		tokens = append(tokens, stem(field))
+	}
+	return tokens
+}
+
+// stem strips common English inflections so "bets", "betting" and "bet" compare equal.
+// It is deliberately conservative: "-er" is left alone so "better" never becomes "bet".
+func stem(word string) string {
+	switch {
+	case len(word) > 5 && strings.HasSuffix(word, "ing"):
+		return undouble(word[:len(word)-3])
+	case len(word) > 4 && strings.HasSuffix(word, "ed"):
+		return undouble(word[:len(word)-2])
+	case len(word) > 4 && (strings.HasSuffix(word, "ches") || strings.HasSuffix(word, "shes") ||
+		strings.HasSuffix(word, "xes") || strings.HasSuffix(word, "sses")):
+		return word[:len(word)-2]
+	case len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") &&
+		!strings.HasSuffix(word, "us") && !strings.HasSuffix(word, "is"):
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// undouble turns "bett" back into "bet" after a suffix was removed, keeping words like "kill"
+func undouble(word string) string {
+	n := len(word)
+	if n >= 2 && word[n-1] == word[n-2] && !strings.ContainsRune("lsz", rune(word[n-1])) &&
+		!strings.ContainsRune("aeiou", rune(word[n-1])) {
+		return word[:n-1]
+	}
+	return word
+}
+
+// matchesAt reports whether phrase occurs in tokens starting at position i
+func matchesAt(tokens, phrase []string, i int) bool {
+	if i+len(phrase) > len(tokens) {
+		return false
+	}
+	for j, word := range phrase {
+		if tokens[i+j] != word {
+			return false
+		}
+	}
+	return true
+}
+
+// ModerateText checks the title and description against the active keyword rules.
+// Rules match whole words only, after leetspeak decoding and stemming, and are ignored
+// where they fall inside an allow-listed phrase. A match in a "reject" category rejects
+// the content; a match in a "flag" category marks it for human review.
+func ModerateText(title, description string) Verdict {
+	rules := activeRules()
+	tokens := normalizeText(title + " " + description)
+
+	// Mark the words covered by allow-listed phrases
+	allowed := make([]bool, len(tokens))
+	for _, phrase := range rules.allow {
+		for i := range tokens {
+			if matchesAt(tokens, phrase, i) {
+				for j := range phrase {
+					allowed[i+j] = true
+				}
+			}
+		}
+	}
+
+	var flagged *compiledRule
+	for r := range rules.rules {
+		rule := &rules.rules[r]
+		for i := range tokens {
+			if !matchesAt(tokens, rule.tokens, i) || coveredByAllow(allowed, i, len(rule.tokens)) {
+				continue
+			}
+
+			if rule.severity == SeverityReject {
+				return Verdict{
+					Approved:   false,
+					Confidence: 0.9,
+					Reason:     "Contains inappropriate keyword: " + rule.Term,
+				}
+			}
+			if flagged == nil {
+				flagged = rule
+			}
+		}
+	}
+
+	if flagged != nil {
+		return Verdict{
+			Approved:   true,
+			Flagged:    true,
+			Confidence: 0.5,
+			Reason:     "Contains keyword needing review (" + flagged.Category + "): " + flagged.Term,
 		}
 	}
 
 	// If we get here, the text seems safe
-	return true, 1.0, ""
+	return Verdict{Approved: true, Confidence: 1.0}
+}
+
+// coveredByAllow reports whether every word of a match is part of an allow-listed phrase
+func coveredByAllow(allowed []bool, start, length int) bool {
+	for i := start; i < start+length; i++ {
+		if !allowed[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// keywordModerator is the provider wrapper around ModerateText
+type keywordModerator struct{}
+
+// Name returns the provider name
+func (keywordModerator) Name() string {
+	return "keyword"
+}
+
+// Moderate checks the title and description against the keyword rules
+func (keywordModerator) Moderate(content Content) (Verdict, error) {
+	return ModerateText(content.Title, content.Description), nil
 }
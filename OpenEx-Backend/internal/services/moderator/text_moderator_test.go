@@ -0,0 +1,185 @@
+package moderator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"empty", "", []string{}},
+		{"lowercases and splits on punctuation", "Selling: Old NOTES, cheap.", []string{"sell", "old", "note", "cheap"}},
+		{"decodes leetspeak inside words", "Free B00ZE", []string{"free", "booze"}},
+		{"exclamation marks at the edges are punctuation", "Wow!!! great", []string{"wow", "great"}},
+		{"exclamation mark inside a word is a letter", "h!gh", []string{"high"}},
+		{"numbers without letters are kept", "iPhone 11 for 500", []string{"iphone", "11", "for", "500"}},
+		{"stems after decoding", "b3tting b3ts", []string{"bet", "bet"}},
+		{"dots split spelled out words", "b.e.t", []string{"b", "e", "t"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeText(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("normalizeText(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStem(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		{"bet", "bet"},
+		{"bets", "bet"},
+		{"betting", "bet"},
+		{"betted", "bet"},
+		{"better", "better"},
+		{"killing", "kill"},
+		{"ring", "ring"},
+		{"seed", "seed"},
+		{"boxes", "box"},
+		{"watches", "watch"},
+		{"classes", "class"},
+		{"glass", "glass"},
+		{"bus", "bus"},
+		{"campus", "campus"},
+		{"analysis", "analysis"},
+	}
+
+	for _, tt := range tests {
+		if got := stem(tt.word); got != tt.want {
+			t.Errorf("stem(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
+
+// useRules activates a rule set for the duration of a test
+func useRules(t *testing.T, set RuleSet) {
+	t.Helper()
+	previous := CurrentRules()
+	if err := SetRules(set); err != nil {
+		t.Fatalf("SetRules() error = %v", err)
+	}
+	t.Cleanup(func() { SetRules(previous) })
+}
+
+func TestModerateText(t *testing.T) {
+	useRules(t, defaultRules)
+
+	tests := []struct {
+		name        string
+		title       string
+		description string
+		approved    bool
+		flagged     bool
+		reason      string
+	}{
+		{"clean", "Calculus notes", "First year, barely used", true, false, ""},
+		{"words containing a rule are not matched", "Assassin's Creed", "PS4 game, comes with the alphabet poster", true, false, ""},
+		{"allow-listed phrase", "Adult education textbook", "Young adult novels too", true, false, ""},
+		{"allow-listed phrase in the description", "Garden supplies", "Weed killer, half full", true, false, ""},
+		{"reject category", "Adult magazines", "", false, false, "adult"},
+		{"rule outside the allowed phrase still matches", "Adult education notes", "and adult films", false, false, "adult"},
+		{"flag category", "Betting tips", "", true, true, "gambling"},
+		{"leetspeak is decoded", "c0caine", "", false, false, "cocaine"},
+		{"reject wins over flag", "Casino night", "with cocaine", false, false, "cocaine"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ModerateText(tt.title, tt.description)
+			if got.Approved != tt.approved || got.Flagged != tt.flagged || !strings.Contains(got.Reason, tt.reason) {
+				t.Errorf("ModerateText(%q, %q) = %+v; want approved %v, flagged %v, reason containing %q",
+					tt.title, tt.description, got, tt.approved, tt.flagged, tt.reason)
+			}
+		})
+	}
+}
+
+func TestModerateTextAllowOverride(t *testing.T) {
+	useRules(t, RuleSet{
+		Categories: map[string]string{"weapons": SeverityFlag},
+		Rules:      []Rule{{Term: "gun", Category: "weapons"}},
+		Allow:      []string{"glue gun"},
+	})
+
+	if got := ModerateText("Hot glue gun", ""); !got.Approved || got.Flagged {
+		t.Errorf("ModerateText() with allow-listed phrase = %+v, want approved", got)
+	}
+	if got := ModerateText("Toy gun", ""); !got.Flagged {
+		t.Errorf("ModerateText() = %+v, want flagged", got)
+	}
+	// The default rules are no longer active
+	if got := ModerateText("Adult magazines", ""); !got.Approved || got.Flagged {
+		t.Errorf("ModerateText() with replaced rules = %+v, want approved", got)
+	}
+}
+
+func TestCompileRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		set     RuleSet
+		wantErr string
+	}{
+		{"default rules", defaultRules, ""},
+		{"empty", RuleSet{}, ""},
+		{
+			"invalid severity",
+			RuleSet{Categories: map[string]string{"spam": "block"}},
+			"invalid severity",
+		},
+		{
+			"unknown category",
+			RuleSet{Categories: map[string]string{"spam": SeverityFlag}, Rules: []Rule{{Term: "crypto", Category: "scam"}}},
+			"unknown category",
+		},
+		{
+			"rule without words",
+			RuleSet{Categories: map[string]string{"spam": SeverityFlag}, Rules: []Rule{{Term: " !!! ", Category: "spam"}}},
+			"no matchable words",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compiled, err := compileRules(tt.set)
+			if tt.wantErr == "" {
+				if err != nil || compiled == nil {
+					t.Errorf("compileRules() error = %v, want none", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("compileRules() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCompileRulesNormalizesTerms(t *testing.T) {
+	compiled, err := compileRules(RuleSet{
+		Categories: map[string]string{"gambling": SeverityFlag},
+		Rules:      []Rule{{Term: "Sports BETTING", Category: "gambling"}},
+		Allow:      []string{"", "Bet365 review"},
+	})
+	if err != nil {
+		t.Fatalf("compileRules() error = %v", err)
+	}
+
+	if got, want := compiled.rules[0].tokens, []string{"sport", "bet"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("rule tokens = %q, want %q", got, want)
+	}
+	if compiled.rules[0].severity != SeverityFlag {
+		t.Errorf("rule severity = %q, want %q", compiled.rules[0].severity, SeverityFlag)
+	}
+	if len(compiled.allow) != 1 {
+		t.Errorf("allow phrases = %q, want the empty one dropped", compiled.allow)
+	}
+}
@@ -0,0 +1,111 @@
+package moderator
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Thresholds split a safety score into the three outcomes. Content scoring at or above
+// Approve is published, content below Reject is rejected, and the rest is reviewed by a human.
+type Thresholds struct {
+	Approve float64 `json:"approve"`
+	Reject  float64 `json:"reject"`
+}
+
+// DefaultThresholds apply to any kind or category without its own configuration
+var DefaultThresholds = Thresholds{Approve: 0.75, Reject: 0.3}
+
+var (
+	thresholdsMu sync.RWMutex
+	thresholds   = map[string]Thresholds{}
+)
+
+// thresholdKey builds the lookup key for a kind, optionally narrowed to a category
+func thresholdKey(kind, category string) string {
+	if category == "" {
+		return strings.ToLower(kind)
+	}
+	return strings.ToLower(kind) + "/" + strings.ToLower(category)
+}
+
+// ThresholdsFor returns the thresholds for a category of a kind, falling back to the
+// kind's thresholds and then to DefaultThresholds
+func ThresholdsFor(kind, category string) Thresholds {
+	thresholdsMu.RLock()
+	defer thresholdsMu.RUnlock()
+
+	if category != "" {
+		if t, ok := thresholds[thresholdKey(kind, category)]; ok {
+			return t
+		}
+	}
+	if t, ok := thresholds[thresholdKey(kind, "")]; ok {
+		return t
+	}
+	return DefaultThresholds
+}
+
+// SetThresholds replaces the configured thresholds. Keys are "kind" or "kind/category".
+func SetThresholds(config map[string]Thresholds) error {
+	normalized := make(map[string]Thresholds, len(config))
+	for key, t := range config {
+		if t.Reject < 0 || t.Approve > 1 || t.Reject > t.Approve {
+			return fmt.Errorf("thresholds for %q must satisfy 0 <= reject <= approve <= 1", key)
+		}
+		normalized[strings.ToLower(key)] = t
+	}
+
+	thresholdsMu.Lock()
+	thresholds = normalized
+	thresholdsMu.Unlock()
+	return nil
+}
+
+// ParseThresholds reads the MODERATION_THRESHOLDS format, a comma-separated list of
+// key=approve:reject pairs such as "item=0.75:0.3,service/tutoring=0.6:0.2"
+func ParseThresholds(value string) (map[string]Thresholds, error) {
+	config := map[string]Thresholds{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		key, scores, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid threshold entry %q, expected key=approve:reject", entry)
+		}
+		approveValue, rejectValue, ok := strings.Cut(scores, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid threshold entry %q, expected key=approve:reject", entry)
+		}
+
+		approve, err := strconv.ParseFloat(strings.TrimSpace(approveValue), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid approve threshold in %q: %w", entry, err)
+		}
+		reject, err := strconv.ParseFloat(strings.TrimSpace(rejectValue), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid reject threshold in %q: %w", entry, err)
+		}
+		config[strings.TrimSpace(key)] = Thresholds{Approve: approve, Reject: reject}
+	}
+	return config, nil
+}
+
+// loadThresholds applies MODERATION_THRESHOLDS, keeping the defaults if it is invalid
+func loadThresholds() error {
+	value := strings.TrimSpace(os.Getenv("MODERATION_THRESHOLDS"))
+	if value == "" {
+		return nil
+	}
+
+	config, err := ParseThresholds(value)
+	if err != nil {
+		return err
+	}
+	return SetThresholds(config)
+}
@@ -0,0 +1,274 @@
+package notify
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"OpenEx-Backend/internal/config"
+	"OpenEx-Backend/internal/models"
+	"OpenEx-Backend/internal/services/email"
+)
+
+// send wraps a message body in the common OpenEx email layout and sends it, logging failures
+func send(to, subject, heading, body, linkPath, linkText string) {
+	htmlContent := fmt.Sprintf(`
+<html>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto;">
+    <div style="background-color: #f7f7f7; padding: 20px; border-radius: 5px; margin-bottom: 20px;">
+        <h1 style="color: #4a6ee0; margin: 0;">%s</h1>
+    </div>
+
+    <div style="background-color: #ffffff; padding: 20px; border-radius: 5px; border: 1px solid #eee;">
+        %s
+
+        <div style="text-align: center; margin: 30px 0;">
+            <a href="%s%s" style="background-color: #4a6ee0; color: white; padding: 12px 20px; text-decoration: none; border-radius: 4px; font-weight: bold;">%s</a>
+        </div>
+    </div>
+
+    <div style="text-align: center; margin-top: 20px; color: #777; font-size: 0.8em;">
+        <p>This is an automated message from OpenEx.</p>
+    </div>
+</body>
+</html>
+`, heading, body, config.FrontendURL(), linkPath, linkText)
+
+	if err := email.SendEmail(to, subject, htmlContent); err != nil {
+		log.Printf("Error sending \"%s\" email to %s: %v", subject, to, err)
+	} else {
+		log.Printf("Sent \"%s\" email to %s", subject, to)
+	}
+}
+
+// ListingRejected tells an owner that their item or service was rejected and why
+func ListingRejected(owner models.User, listingType, title, reason string) {
+	body := fmt.Sprintf(`
+        <p>Hi %s,</p>
+        <p>Your %s <strong>%s</strong> was not approved for the OpenEx marketplace.</p>
+        <p><strong>Reason:</strong> %s</p>
+        <p>You can edit the listing to address this and resubmit it for review.</p>`,
+		owner.Name, listingType, title, reason)
+
+	send(owner.Email, fmt.Sprintf("Your %s \"%s\" was not approved", listingType, title),
+		"Listing Not Approved", body, listingPath(listingType), "Edit and Resubmit")
+}
+
+// ListingRemoved tells an owner that their listing was taken down after users reported it
+func ListingRemoved(owner models.User, listingType, title, reason string) {
+	body := fmt.Sprintf(`
+        <p>Hi %s,</p>
+        <p>Your %s <strong>%s</strong> was removed from the OpenEx marketplace after other users reported it and a moderator reviewed the reports.</p>
+        <p><strong>Reason:</strong> %s</p>
+        <p>If you think this was a mistake, please reply to this email.</p>`,
+		owner.Name, listingType, title, reason)
+
+	send(owner.Email, fmt.Sprintf("Your %s \"%s\" was removed", listingType, title),
+		"Listing Removed", body, listingPath(listingType), "View My Listings")
+}
+
+// AppealDecided tells an owner whether their appeal against a rejection was granted
+func AppealDecided(owner models.User, listingType, title string, granted bool, note string) {
+	subject := fmt.Sprintf("Your appeal for \"%s\" was denied", title)
+	heading := "Appeal Denied"
+	outcome := fmt.Sprintf("A moderator reviewed your appeal and the rejection of your %s <strong>%s</strong> stands. You can still edit the listing and resubmit it.", listingType, title)
+	linkText := "Edit and Resubmit"
+	if granted {
+		subject = fmt.Sprintf("Your appeal for \"%s\" was granted", title)
+		heading = "Appeal Granted"
+		outcome = fmt.Sprintf("A moderator reviewed your appeal and your %s <strong>%s</strong> is now live on the OpenEx marketplace.", listingType, title)
+		linkText = "View My Listings"
+	}
+
+	body := fmt.Sprintf(`
+        <p>Hi %s,</p>
+        <p>%s</p>`, owner.Name, outcome)
+	if note != "" {
+		body += fmt.Sprintf(`
+        <p><strong>Moderator's note:</strong> %s</p>`, note)
+	}
+
+	send(owner.Email, subject, heading, body, listingPath(listingType), linkText)
+}
+
+// ExpiryReminder tells an owner that their listing or request is about to expire, with a link
+// that renews it in one click
+func ExpiryReminder(owner models.User, listingType, title string, expiresAt time.Time, renewToken string) {
+	body := fmt.Sprintf(`
+        <p>Hi %s,</p>
+        <p>Your %s <strong>%s</strong> will expire on %s and disappear from the OpenEx marketplace.</p>
+        <p>If it is still relevant, renew it with the button below. Otherwise there is nothing to do.</p>`,
+		owner.Name, listingType, title, expiresAt.Format("January 2, 2006"))
+
+	send(owner.Email, fmt.Sprintf("Your %s \"%s\" expires soon", listingType, title),
+		"Expiring Soon", body, "/app/renew?token="+url.QueryEscape(renewToken), "Renew")
+}
+
+// RequestMatched tells a buyer that a newly published item fits one of their requested items
+func RequestMatched(buyer models.User, requestTitle string, itemID uint, itemTitle string, price float64) {
+	body := fmt.Sprintf(`
+        <p>Hi %s,</p>
+        <p>A new listing on OpenEx looks like what you asked for in <strong>%s</strong>:</p>
+        <p><strong>%s</strong> for ₹%.2f (item #%d)</p>
+        <p>Have a look and send the seller a request if it fits.</p>`,
+		buyer.Name, requestTitle, itemTitle, price, itemID)
+
+	send(buyer.Email, fmt.Sprintf("New listing for your request \"%s\"", requestTitle),
+		"We Found a Match", body, listingPath("requested item"), "View My Requests")
+}
+
+// OfferRejected tells a seller that moderation rejected their offer on a requested item
+func OfferRejected(seller models.User, requestTitle, reason string) {
+	body := fmt.Sprintf(`
+        <p>Hi %s,</p>
+        <p>Your offer on the request <strong>%s</strong> was not approved, so the buyer will not see it.</p>
+        <p><strong>Reason:</strong> %s</p>
+        <p>You are welcome to make a new offer that addresses this.</p>`,
+		seller.Name, requestTitle, reason)
+
+	send(seller.Email, fmt.Sprintf("Your offer on \"%s\" was not approved", requestTitle),
+		"Offer Not Approved", body, listingPath("offer"), "View Requests")
+}
+
+// RequestRejected tells a buyer or requester that moderation rejected their requested item or
+// service request
+func RequestRejected(owner models.User, requestType, title, reason string) {
+	body := fmt.Sprintf(`
+        <p>Hi %s,</p>
+        <p>Your %s <strong>%s</strong> was not approved, so it will not be shown to other users.</p>
+        <p><strong>Reason:</strong> %s</p>
+        <p>You are welcome to post a new request that addresses this.</p>`,
+		owner.Name, requestType, title, reason)
+
+	send(owner.Email, fmt.Sprintf("Your %s \"%s\" was not approved", requestType, title),
+		"Request Not Approved", body, listingPath(requestType), "View My Requests")
+}
+
+// OfferDeclined tells a seller that the buyer did not take their offer
+func OfferDeclined(seller models.User, requestTitle, reason string) {
+	body := fmt.Sprintf(`
+        <p>Hi %s,</p>
+        <p>The buyer declined your offer on the request <strong>%s</strong>.</p>`,
+		seller.Name, requestTitle)
+	if reason != "" {
+		body += fmt.Sprintf(`
+        <p>%s</p>`, reason)
+	}
+
+	send(seller.Email, fmt.Sprintf("Your offer on \"%s\" was declined", requestTitle),
+		"Offer Declined", body, listingPath("offer"), "View Requests")
+}
+
+// ProposalReceived tells a requester that a provider bid on their service request
+func ProposalReceived(requester models.User, requestTitle, providerName string, price float64, estimatedCompletion time.Time) {
+	body := fmt.Sprintf(`
+        <p>Hi %s,</p>
+        <p><strong>%s</strong> sent a proposal for your service request <strong>%s</strong>:</p>
+        <p>₹%.2f, done by %s</p>
+        <p>Compare the proposals on your request and choose the one you like.</p>`,
+		requester.Name, providerName, requestTitle, price, estimatedCompletion.Format("January 2, 2006"))
+
+	send(requester.Email, fmt.Sprintf("New proposal for \"%s\"", requestTitle),
+		"You Have a New Proposal", body, listingPath("service request"), "View Proposals")
+}
+
+// ProposalAccepted tells a provider that the requester chose their proposal
+func ProposalAccepted(provider models.User, requestTitle string) {
+	body := fmt.Sprintf(`
+        <p>Hi %s,</p>
+        <p>Your proposal for the service request <strong>%s</strong> was chosen. The job is yours!</p>
+        <p>Log in to see the requester's contact details and get started.</p>`,
+		provider.Name, requestTitle)
+
+	send(provider.Email, fmt.Sprintf("Your proposal for \"%s\" was chosen", requestTitle),
+		"Proposal Accepted", body, listingPath("proposal"), "View My Jobs")
+}
+
+// ProposalDeclined tells a provider that the requester did not choose their proposal
+func ProposalDeclined(provider models.User, requestTitle, reason string) {
+	body := fmt.Sprintf(`
+        <p>Hi %s,</p>
+        <p>Your proposal for the service request <strong>%s</strong> was declined.</p>`,
+		provider.Name, requestTitle)
+	if reason != "" {
+		body += fmt.Sprintf(`
+        <p>%s</p>`, reason)
+	}
+
+	send(provider.Email, fmt.Sprintf("Your proposal for \"%s\" was declined", requestTitle),
+		"Proposal Declined", body, listingPath("proposal"), "View Service Requests")
+}
+
+// sessionTime formats the start of a booked session for emails
+func sessionTime(startsAt time.Time) string {
+	return startsAt.Format("Monday, January 2, 2006 at 3:04 PM")
+}
+
+// BookingRequested tells a provider that someone booked one of their slots
+func BookingRequested(provider models.User, requesterName, serviceTitle string, startsAt time.Time) {
+	body := fmt.Sprintf(`
+        <p>Hi %s,</p>
+        <p><strong>%s</strong> booked your service <strong>%s</strong> for %s.</p>
+        <p>Please confirm or cancel the booking so they know where they stand.</p>`,
+		provider.Name, requesterName, serviceTitle, sessionTime(startsAt))
+
+	send(provider.Email, fmt.Sprintf("New booking for \"%s\"", serviceTitle),
+		"New Booking", body, listingPath("booking"), "View Bookings")
+}
+
+// BookingConfirmed tells a requester that the provider confirmed their booking
+func BookingConfirmed(requester models.User, serviceTitle string, startsAt time.Time) {
+	body := fmt.Sprintf(`
+        <p>Hi %s,</p>
+        <p>Your booking of <strong>%s</strong> for %s is confirmed.</p>
+        <p>Log in to see the provider's contact details.</p>`,
+		requester.Name, serviceTitle, sessionTime(startsAt))
+
+	send(requester.Email, fmt.Sprintf("Your booking of \"%s\" is confirmed", serviceTitle),
+		"Booking Confirmed", body, listingPath("booking"), "View Bookings")
+}
+
+// BookingCancelled tells one party that the other cancelled a booking
+func BookingCancelled(recipient models.User, serviceTitle string, startsAt time.Time, reason string) {
+	body := fmt.Sprintf(`
+        <p>Hi %s,</p>
+        <p>The booking of <strong>%s</strong> for %s was cancelled.</p>`,
+		recipient.Name, serviceTitle, sessionTime(startsAt))
+	if reason != "" {
+		body += fmt.Sprintf(`
+        <p><strong>Reason:</strong> %s</p>`, reason)
+	}
+
+	send(recipient.Email, fmt.Sprintf("Booking of \"%s\" cancelled", serviceTitle),
+		"Booking Cancelled", body, listingPath("booking"), "View Bookings")
+}
+
+// BookingReminder reminds one party of a confirmed session coming up soon
+func BookingReminder(recipient models.User, serviceTitle, otherName string, startsAt time.Time) {
+	body := fmt.Sprintf(`
+        <p>Hi %s,</p>
+        <p>This is a reminder of your session <strong>%s</strong> with <strong>%s</strong> on %s.</p>
+        <p>If you can no longer make it, please cancel the booking so the other side knows.</p>`,
+		recipient.Name, serviceTitle, otherName, sessionTime(startsAt))
+
+	send(recipient.Email, fmt.Sprintf("Reminder: \"%s\" is coming up", serviceTitle),
+		"Upcoming Session", body, listingPath("booking"), "View Bookings")
+}
+
+// listingPath returns the frontend page where an owner manages listings of the given type
+func listingPath(listingType string) string {
+	switch listingType {
+	case "service":
+		return "/app/my-services"
+	case "service request":
+		return "/app/my-service-requests"
+	case "requested item", "offer":
+		return "/app/buyRequests"
+	case "proposal":
+		return "/app/service-requests"
+	case "booking":
+		return "/app/bookings"
+	}
+	return "/app/listItem"
+}
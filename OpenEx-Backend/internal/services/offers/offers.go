@@ -0,0 +1,36 @@
+package offers
+
+import (
+	"OpenEx-Backend/internal/database"
+	"OpenEx-Backend/internal/models"
+	"OpenEx-Backend/internal/services/notify"
+)
+
+// ActiveStatuses are the statuses of offers that are still in play
+var ActiveStatuses = []string{"pending", "needs_review", "open"}
+
+// DeclineActive declines the active offers on a requested item that is no longer taking them
+// and tells their sellers why. It runs whenever a request is closed or expires, or its buyer
+// deletes their account.
+func DeclineActive(requestedItemID uint, reason string) error {
+	var active []models.Offer
+	if err := database.DB.Preload("Seller").Preload("RequestedItem").
+		Where("requested_item_id = ? AND status IN ?", requestedItemID, ActiveStatuses).
+		Find(&active).Error; err != nil {
+		return err
+	}
+
+	for _, offer := range active {
+		// An offer withdrawn or accepted meanwhile keeps its status, and its seller hears nothing
+		result := database.DB.Model(&models.Offer{}).
+			Where("id = ? AND status IN ?", offer.ID, ActiveStatuses).
+			Update("status", "declined")
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			go notify.OfferDeclined(offer.Seller, offer.RequestedItem.Title, reason)
+		}
+	}
+	return nil
+}
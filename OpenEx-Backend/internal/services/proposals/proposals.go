@@ -0,0 +1,33 @@
+package proposals
+
+import (
+	"OpenEx-Backend/internal/database"
+	"OpenEx-Backend/internal/models"
+	"OpenEx-Backend/internal/services/notify"
+)
+
+// DeclineOpen declines the open proposals on a service request that is no longer taking them
+// and tells their providers why. It runs whenever a request leaves the open state or is
+// hidden, whether a user, a moderator or a background job moved it.
+func DeclineOpen(serviceRequestID uint, reason string) error {
+	var open []models.Proposal
+	if err := database.DB.Preload("Provider").Preload("ServiceRequest").
+		Where("service_request_id = ? AND status = ?", serviceRequestID, "open").
+		Find(&open).Error; err != nil {
+		return err
+	}
+
+	for _, proposal := range open {
+		// A proposal withdrawn meanwhile keeps its status, and its provider hears nothing
+		result := database.DB.Model(&models.Proposal{}).
+			Where("id = ? AND status = ?", proposal.ID, "open").
+			Update("status", "declined")
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			go notify.ProposalDeclined(proposal.Provider, proposal.ServiceRequest.Title, reason)
+		}
+	}
+	return nil
+}
@@ -3,11 +3,22 @@ package worker
 import (
 	"OpenEx-Backend/internal/database"
 	"OpenEx-Backend/internal/models"
+	"OpenEx-Backend/internal/scheduler"
+	"OpenEx-Backend/internal/services/audit"
+	"OpenEx-Backend/internal/services/matcher"
+	"OpenEx-Backend/internal/services/moderationlog"
 	"OpenEx-Backend/internal/services/moderator"
+	"OpenEx-Backend/internal/services/notify"
+	"context"
+	"fmt"
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // GetWaitPeriod returns the configured wait period for auto-approval
@@ -32,11 +43,18 @@ func GetWaitPeriod() time.Duration {
 		time.Duration(seconds)*time.Second
 }
 
-// StartAutoApprover starts the background worker for auto-approving items
-func StartAutoApprover() {
+// StartAutoApprover registers the auto-approval jobs and starts the moderation queue
+func StartAutoApprover(jobs *scheduler.Scheduler) error {
 	// Initialize moderator service
 	moderator.Initialize()
 
+	if mode := SubmitMode(); mode != SubmitModeOff {
+		log.Printf("New submissions are moderated immediately (%s mode)", mode)
+		if mode == SubmitModeQueue {
+			startSubmissionQueue(jobs)
+		}
+	}
+
 	// Set up periodic check
 	waitPeriod := GetWaitPeriod()
 	log.Printf("Auto-approver will process items after %v of pending status", waitPeriod)
@@ -46,84 +64,294 @@ func StartAutoApprover() {
 	if waitPeriod < time.Minute*5 {
 		checkInterval = 10 * time.Second // Check every 10 seconds for short wait periods
 	}
-	ticker := time.NewTicker(checkInterval)
 
-	go func() {
-		for range ticker.C {
-			processExpiredPendingItems()
-			processExpiredPendingServices()
-		}
-	}()
+	if err := jobs.Register(scheduler.Job{
+		Name:      "auto-approve-items",
+		Interval:  checkInterval,
+		Exclusive: true,
+		Timeout:   claimTimeout,
+		Run:       processExpiredPendingItems,
+	}); err != nil {
+		return err
+	}
+	if err := jobs.Register(scheduler.Job{
+		Name:      "auto-approve-services",
+		Interval:  checkInterval,
+		Exclusive: true,
+		Timeout:   claimTimeout,
+		Run:       processExpiredPendingServices,
+	}); err != nil {
+		return err
+	}
 
 	log.Println("Auto-approver worker started")
+	return nil
 }
 
-// processExpiredPendingItems processes items that have been pending for too long
-func processExpiredPendingItems() {
-	var items []models.Item
+// claimBatchSize is how many rows an instance claims per batch
+const claimBatchSize = 50
 
-	// Find items that have been pending too long
-	cutoffTime := time.Now().Add(-GetWaitPeriod())
-	if err := database.DB.Where("status = ? AND created_at < ?", "pending", cutoffTime).Find(&items).Error; err != nil {
-		log.Printf("Error finding pending items: %v", err)
-		return
+// claimLease is how long a claim holds if the instance dies before releasing it
+const claimLease = 10 * time.Minute
+
+// claimTimeout limits a run of the auto-approve jobs, so a run ends before its claims expire
+const claimTimeout = claimLease / 2
+
+// settled selects claimed rows that moderation moved out of "pending". Rows it failed to
+// update stay claimed until the lease runs out, so the next batch doesn't pick them up again.
+func settled(db *gorm.DB) *gorm.DB {
+	return db.Where("status <> ?", "pending")
+}
+
+// releaseClaim releases the claimed rows moderation is done with. After an error, such as a
+// shutdown, everything is released so the rest is picked up right away.
+func releaseClaim(model interface{}, token string, err error) {
+	var releaseErr error
+	if err != nil {
+		releaseErr = database.ReleaseClaim(model, token)
+	} else {
+		releaseErr = database.ReleaseClaim(model, token, settled)
+	}
+	if releaseErr != nil {
+		log.Printf("Error releasing claimed rows: %v", releaseErr)
 	}
+}
 
-	log.Printf("Found %d pending items ready for auto-processing", len(items))
+// expiredPending selects listings whose waiting period is over
+func expiredPending(db *gorm.DB) *gorm.DB {
+	cutoffTime := time.Now().Add(-GetWaitPeriod())
+	return db.Where("status = ? AND COALESCE(submitted_at, created_at) < ?", "pending", cutoffTime)
+}
 
-	for _, item := range items {
-		// Evaluate item content
-		approved, confidence, reason := moderator.EvaluateContent(
-			item.Title,
-			item.Description,
-			item.Image,
-		)
+// processExpiredPendingItems processes items that have been pending for too long. Items are
+// claimed in batches, so other instances working through the backlog skip them.
+func processExpiredPendingItems(ctx context.Context) error {
+	processed := 0
+	for {
+		token, claimed, err := database.Claim(ctx, &models.Item{}, claimBatchSize, claimLease, expiredPending)
+		if err != nil {
+			return fmt.Errorf("claiming pending items: %w", err)
+		}
+		if claimed == 0 {
+			break
+		}
 
-		if approved {
-			item.Status = "approved"
-			log.Printf("Auto-approved item #%d (confidence: %.2f)", item.ID, confidence)
-		} else {
-			item.Status = "rejected"
-			log.Printf("Auto-rejected item #%d: %s (confidence: %.2f)", item.ID, reason, confidence)
+		err = moderateClaimedItems(ctx, token)
+		releaseClaim(&models.Item{}, token, err)
+		if err != nil {
+			return err
 		}
+		processed += int(claimed)
+	}
 
-		if err := database.DB.Save(&item).Error; err != nil {
-			log.Printf("Error updating item #%d: %v", item.ID, err)
+	if processed > 0 {
+		log.Printf("Auto-processed %d pending items", processed)
+	}
+	return nil
+}
+
+// moderateClaimedItems moderates the items claimed with token
+func moderateClaimedItems(ctx context.Context, token string) error {
+	var items []models.Item
+	if err := database.DB.WithContext(ctx).Preload("User").
+		Where("claim_token = ? AND status = ?", token, "pending").
+		Find(&items).Error; err != nil {
+		return fmt.Errorf("finding claimed items: %w", err)
+	}
+
+	for i := range items {
+		// Stop between items on shutdown; the rest are picked up on the next start
+		if err := ctx.Err(); err != nil {
+			return err
 		}
+		moderateItem(&items[i], "auto-approver", false)
 	}
+	return nil
 }
 
 // processExpiredPendingServices processes services that have been pending for too long
-func processExpiredPendingServices() {
+func processExpiredPendingServices(ctx context.Context) error {
+	processed := 0
+	for {
+		token, claimed, err := database.Claim(ctx, &models.Service{}, claimBatchSize, claimLease, expiredPending)
+		if err != nil {
+			return fmt.Errorf("claiming pending services: %w", err)
+		}
+		if claimed == 0 {
+			break
+		}
+
+		err = moderateClaimedServices(ctx, token)
+		releaseClaim(&models.Service{}, token, err)
+		if err != nil {
+			return err
+		}
+		processed += int(claimed)
+	}
+
+	if processed > 0 {
+		log.Printf("Auto-processed %d pending services", processed)
+	}
+	return nil
+}
+
+// moderateClaimedServices moderates the services claimed with token
+func moderateClaimedServices(ctx context.Context, token string) error {
 	var services []models.Service
+	if err := database.DB.WithContext(ctx).Preload("User").
+		Where("claim_token = ? AND status = ?", token, "pending").
+		Find(&services).Error; err != nil {
+		return fmt.Errorf("finding claimed services: %w", err)
+	}
 
-	// Find services that have been pending too long
-	cutoffTime := time.Now().Add(-GetWaitPeriod())
-	if err := database.DB.Where("status = ? AND created_at < ?", "pending", cutoffTime).Find(&services).Error; err != nil {
-		log.Printf("Error finding pending services: %v", err)
-		return
+	for i := range services {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		moderateService(&services[i], "auto-approver", false)
 	}
+	return nil
+}
 
-	log.Printf("Found %d pending services ready for auto-processing", len(services))
+// moderationColumns are the columns an automatic moderation outcome sets on a listing
+func moderationColumns(status, rejectionReason, reviewReason string, reviewPriority int) map[string]interface{} {
+	return map[string]interface{}{
+		"status":           status,
+		"rejection_reason": rejectionReason,
+		"review_reason":    reviewReason,
+		"review_priority":  reviewPriority,
+	}
+}
 
-	for _, service := range services {
-		// Evaluate service content
-		approved, confidence, reason := moderator.EvaluateContent(
-			service.Title,
-			service.Description,
-			"", // Services typically don't have images
-		)
+// sameSubmission matches a listing that has not been resubmitted since submittedAt was read, so
+// a verdict on content the owner has since edited is dropped; the edit queues its own check
+func sameSubmission(submittedAt *time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if submittedAt == nil {
+			return db.Where("submitted_at IS NULL")
+		}
+		// The column keeps less precision than the value read back from a fresh insert
+		return db.Where("submitted_at BETWEEN ? AND ?", submittedAt.Add(-time.Second), submittedAt.Add(time.Second))
+	}
+}
+
+// moderateItem evaluates an item and applies the outcome. When holdRejections is set, content
+// the pipeline would reject stays pending instead, so it is only rejected once the waiting
+// period has passed without a moderator deciding.
+func moderateItem(item *models.Item, actor string, holdRejections bool) {
+	// Evaluate item content
+	result := moderator.Evaluate(moderator.KindItem, moderator.Content{
+		Title:       item.Title,
+		Description: item.Description,
+		ImageURL:    item.Image,
+		Category:    item.Type,
+	})
 
-		if approved {
-			service.Status = "approved"
-			log.Printf("Auto-approved service #%d (confidence: %.2f)", service.ID, confidence)
-		} else {
-			service.Status = "rejected"
-			log.Printf("Auto-rejected service #%d: %s (confidence: %.2f)", service.ID, reason, confidence)
+	previousStatus := item.Status
+	action, columns := "item.auto_approve", moderationColumns("approved", "", "", 0)
+	switch result.Outcome {
+	case moderator.OutcomeReject:
+		if holdRejections {
+			// Leave it pending so a moderator can look at it before the waiting period ends
+			log.Printf("Holding item #%d for the waiting period: %s (safety: %.2f)", item.ID, result.Reason, result.Safety)
+			return
 		}
+		action, columns = "item.auto_reject", moderationColumns("rejected", result.Reason, "", 0)
+		log.Printf("Auto-rejected item #%d: %s (safety: %.2f)", item.ID, result.Reason, result.Safety)
+	case moderator.OutcomeReview:
+		action, columns = "item.flag", moderationColumns("needs_review", "", result.Reason, result.Priority)
+		log.Printf("Sent item #%d to review: %s (safety: %.2f, priority: %d)", item.ID, result.Reason, result.Safety, result.Priority)
+	default:
+		log.Printf("Auto-approved item #%d (safety: %.2f)", item.ID, result.Safety)
+	}
+
+	// The slow pipeline ran on a snapshot; a moderator, a report or an edit by the owner may have
+	// moved the item on since, and their change wins. Only the moderation columns are written.
+	update := database.DB.Model(item).Omit(clause.Associations).
+		Where("status = ?", "pending").
+		Scopes(sameSubmission(item.SubmittedAt)).
+		Updates(columns)
+	if update.Error != nil {
+		log.Printf("Error updating item #%d: %v", item.ID, update.Error)
+		return
+	}
+	if update.RowsAffected == 0 {
+		log.Printf("Skipped item #%d, it left pending while being evaluated", item.ID)
+		return
+	}
+
+	moderationlog.RecordAutomatic("item", item.ID, result)
 
-		if err := database.DB.Save(&service).Error; err != nil {
-			log.Printf("Error updating service #%d: %v", service.ID, err)
+	audit.Record(audit.WorkerActor(actor), audit.Entry{
+		Action:     action,
+		TargetType: "item",
+		TargetID:   item.ID,
+		FromStatus: previousStatus,
+		ToStatus:   item.Status,
+		Reason:     strings.TrimSpace(fmt.Sprintf("%s (safety: %.2f)", result.Reason, result.Safety)),
+	})
+
+	switch item.Status {
+	case "rejected":
+		go notify.ListingRejected(item.User, "item", item.Title, item.RejectionReason)
+	case "approved":
+		go matcher.ItemApproved(*item)
+	}
+}
+
+// moderateService evaluates a service and applies the outcome. See moderateItem.
+func moderateService(service *models.Service, actor string, holdRejections bool) {
+	// Evaluate service content
+	result := moderator.Evaluate(moderator.KindService, moderator.Content{
+		Title:       service.Title,
+		Description: service.Description,
+		Category:    service.Category,
+	})
+
+	previousStatus := service.Status
+	action, columns := "service.auto_approve", moderationColumns("approved", "", "", 0)
+	switch result.Outcome {
+	case moderator.OutcomeReject:
+		if holdRejections {
+			// Leave it pending so a moderator can look at it before the waiting period ends
+			log.Printf("Holding service #%d for the waiting period: %s (safety: %.2f)", service.ID, result.Reason, result.Safety)
+			return
 		}
+		action, columns = "service.auto_reject", moderationColumns("rejected", result.Reason, "", 0)
+		log.Printf("Auto-rejected service #%d: %s (safety: %.2f)", service.ID, result.Reason, result.Safety)
+	case moderator.OutcomeReview:
+		action, columns = "service.flag", moderationColumns("needs_review", "", result.Reason, result.Priority)
+		log.Printf("Sent service #%d to review: %s (safety: %.2f, priority: %d)", service.ID, result.Reason, result.Safety, result.Priority)
+	default:
+		log.Printf("Auto-approved service #%d (safety: %.2f)", service.ID, result.Safety)
+	}
+
+	// Only the moderation columns are written, and only if nothing moved the service on. See moderateItem.
+	update := database.DB.Model(service).Omit(clause.Associations).
+		Where("status = ?", "pending").
+		Scopes(sameSubmission(service.SubmittedAt)).
+		Updates(columns)
+	if update.Error != nil {
+		log.Printf("Error updating service #%d: %v", service.ID, update.Error)
+		return
+	}
+	if update.RowsAffected == 0 {
+		log.Printf("Skipped service #%d, it left pending while being evaluated", service.ID)
+		return
+	}
+
+	moderationlog.RecordAutomatic("service", service.ID, result)
+
+	audit.Record(audit.WorkerActor(actor), audit.Entry{
+		Action:     action,
+		TargetType: "service",
+		TargetID:   service.ID,
+		FromStatus: previousStatus,
+		ToStatus:   service.Status,
+		Reason:     strings.TrimSpace(fmt.Sprintf("%s (safety: %.2f)", result.Reason, result.Safety)),
+	})
+
+	if service.Status == "rejected" {
+		go notify.ListingRejected(service.User, "service", service.Title, service.RejectionReason)
 	}
 }
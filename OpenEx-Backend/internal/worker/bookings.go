@@ -0,0 +1,139 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"OpenEx-Backend/internal/database"
+	"OpenEx-Backend/internal/models"
+	"OpenEx-Backend/internal/scheduler"
+	"OpenEx-Backend/internal/services/audit"
+	"OpenEx-Backend/internal/services/notify"
+)
+
+// BookingReminderWindow returns how long before a confirmed session both parties are reminded,
+// from BOOKING_REMINDER_HOURS (default 24)
+func BookingReminderWindow() time.Duration {
+	if hours, err := strconv.ParseFloat(os.Getenv("BOOKING_REMINDER_HOURS"), 64); err == nil && hours > 0 {
+		return time.Duration(hours * float64(time.Hour))
+	}
+	return 24 * time.Hour
+}
+
+// StartBookingReminders registers the job that reminds parties of upcoming sessions and
+// cancels bookings the provider never confirmed
+func StartBookingReminders(jobs *scheduler.Scheduler) error {
+	log.Printf("Booked sessions are reminded %v in advance", BookingReminderWindow())
+
+	return jobs.Register(scheduler.Job{
+		Name:      "booking-reminders",
+		Interval:  10 * time.Minute,
+		Exclusive: true,
+		Run:       processBookings,
+	})
+}
+
+// processBookings runs the booking steps in order
+func processBookings(ctx context.Context) error {
+	if err := sendBookingReminders(ctx); err != nil {
+		return err
+	}
+	return cancelUnconfirmedBookings(ctx)
+}
+
+// sendBookingReminders emails both parties of confirmed sessions starting within the window
+func sendBookingReminders(ctx context.Context) error {
+	now := time.Now()
+
+	var bookings []models.Booking
+	if err := database.DB.WithContext(ctx).Preload("Service").Preload("Requester").Preload("Provider").
+		Where("status = ? AND reminded_at IS NULL", "confirmed").
+		Where("starts_at > ? AND starts_at <= ?", now, now.Add(BookingReminderWindow())).
+		Find(&bookings).Error; err != nil {
+		return fmt.Errorf("finding upcoming bookings: %w", err)
+	}
+
+	reminded := 0
+	for _, booking := range bookings {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Mark first, so a failing mail server doesn't lead to a reminder every run. Only the
+		// run that set the mark sends the emails, and none does if the mark can't be saved or
+		// the booking was cancelled meanwhile.
+		result := database.DB.Model(&models.Booking{}).
+			Where("id = ? AND status = ? AND reminded_at IS NULL", booking.ID, "confirmed").
+			Update("reminded_at", now)
+		if result.Error != nil {
+			log.Printf("Error marking booking #%d as reminded: %v", booking.ID, result.Error)
+			continue
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		notify.BookingReminder(booking.Requester, booking.Service.Title, booking.Provider.Name, booking.StartsAt)
+		notify.BookingReminder(booking.Provider, booking.Service.Title, booking.Requester.Name, booking.StartsAt)
+		reminded++
+	}
+
+	if reminded > 0 {
+		log.Printf("Sent reminders for %d upcoming bookings", reminded)
+	}
+	return nil
+}
+
+// cancelUnconfirmedBookings cancels requested bookings whose session started before the
+// provider confirmed them, and tells the requester
+func cancelUnconfirmedBookings(ctx context.Context) error {
+	now := time.Now()
+
+	var bookings []models.Booking
+	if err := database.DB.WithContext(ctx).Preload("Service").Preload("Requester").
+		Where("status = ? AND starts_at <= ?", "requested", now).
+		Find(&bookings).Error; err != nil {
+		return fmt.Errorf("finding unconfirmed bookings: %w", err)
+	}
+
+	const reason = "The provider did not confirm the booking before the session started."
+	for _, booking := range bookings {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// The status condition keeps a booking confirmed or cancelled meanwhile untouched
+		result := database.DB.Model(&models.Booking{}).
+			Where("id = ? AND status = ?", booking.ID, "requested").
+			Updates(map[string]interface{}{
+				"status":        "cancelled",
+				"cancel_reason": reason,
+				"cancelled_at":  now,
+			})
+		if result.Error != nil {
+			log.Printf("Error cancelling booking #%d: %v", booking.ID, result.Error)
+			continue
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		audit.Record(audit.WorkerActor("bookings"), audit.Entry{
+			Action:     "booking.cancel",
+			TargetType: "booking",
+			TargetID:   booking.ID,
+			FromStatus: "requested",
+			ToStatus:   "cancelled",
+			Reason:     reason,
+		})
+		notify.BookingCancelled(booking.Requester, booking.Service.Title, booking.StartsAt, reason)
+	}
+
+	if len(bookings) > 0 {
+		log.Printf("Cancelled %d unconfirmed bookings", len(bookings))
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"OpenEx-Backend/internal/database"
+	"OpenEx-Backend/internal/models"
+	"OpenEx-Backend/internal/scheduler"
+	"OpenEx-Backend/internal/services/audit"
+	"OpenEx-Backend/internal/services/expiry"
+	"OpenEx-Backend/internal/services/notify"
+	"OpenEx-Backend/internal/services/offers"
+	"OpenEx-Backend/internal/services/proposals"
+)
+
+// expiringRecord is a live record about to expire, joined with its owner
+type expiringRecord struct {
+	ID        uint
+	Title     string
+	ExpiresAt time.Time
+	OwnerName string
+	Email     string
+}
+
+// StartExpiry registers the job that reminds owners and expires stale records
+func StartExpiry(jobs *scheduler.Scheduler) error {
+	for _, kind := range expiry.Kinds {
+		if ttl := kind.TTL(); ttl > 0 {
+			log.Printf("%s records expire %v after publishing or renewal", kind.Label, ttl)
+		}
+	}
+
+	return jobs.Register(scheduler.Job{
+		Name:       "expire-stale-records",
+		Interval:   time.Hour,
+		RunOnStart: true,
+		Exclusive:  true,
+		Run:        processExpiry,
+	})
+}
+
+// processExpiry runs the expiry steps for every kind that has a TTL
+func processExpiry(ctx context.Context) error {
+	for _, kind := range expiry.Kinds {
+		ttl := kind.TTL()
+		if ttl <= 0 {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := scheduleExpiry(ctx, kind, ttl); err != nil {
+			return err
+		}
+		if err := sendExpiryReminders(ctx, kind); err != nil {
+			return err
+		}
+		if err := expireRecords(ctx, kind); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scheduleExpiry gives live records without an expiry date one, counting from now. New
+// records get theirs on the first run after they go live.
+func scheduleExpiry(ctx context.Context, kind expiry.Kind, ttl time.Duration) error {
+	result := database.DB.WithContext(ctx).Table(kind.Table).
+		Where("status = ? AND expires_at IS NULL", kind.ActiveStatus).
+		Updates(map[string]interface{}{
+			"expires_at":         time.Now().Add(ttl),
+			"expiry_reminded_at": nil,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("scheduling expiry of %s records: %w", kind.Label, result.Error)
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Scheduled expiry for %d %s records", result.RowsAffected, kind.Label)
+	}
+	return nil
+}
+
+// sendExpiryReminders emails owners whose records expire within the reminder window
+func sendExpiryReminders(ctx context.Context, kind expiry.Kind) error {
+	now := time.Now()
+
+	var records []expiringRecord
+	if err := database.DB.WithContext(ctx).Table(kind.Table+" AS t").
+		Select("t.id, t.title, t.expires_at, users.name AS owner_name, users.email").
+		Joins("JOIN users ON users.id = t."+kind.OwnerColumn).
+		Where("t.status = ? AND t.expiry_reminded_at IS NULL", kind.ActiveStatus).
+		Where("t.expires_at > ? AND t.expires_at <= ?", now, now.Add(expiry.ReminderWindow())).
+		Scan(&records).Error; err != nil {
+		return fmt.Errorf("finding expiring %s records: %w", kind.Label, err)
+	}
+
+	for _, record := range records {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		token, err := expiry.RenewToken(kind.TargetType, record.ID, record.ExpiresAt)
+		if err != nil {
+			log.Printf("Error creating renew token for %s #%d: %v", kind.Label, record.ID, err)
+			continue
+		}
+
+		// Mark first, so a failing mail server doesn't lead to a reminder every hour. Only the
+		// run that set the mark sends the email, and none does if the mark can't be saved.
+		result := database.DB.Table(kind.Table).
+			Where("id = ? AND expiry_reminded_at IS NULL", record.ID).
+			Update("expiry_reminded_at", now)
+		if result.Error != nil {
+			log.Printf("Error marking %s #%d as reminded: %v", kind.Label, record.ID, result.Error)
+			continue
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		notify.ExpiryReminder(models.User{Name: record.OwnerName, Email: record.Email},
+			kind.Label, record.Title, record.ExpiresAt, token)
+	}
+	return nil
+}
+
+// expireRecords moves live records past their expiry date to expired
+func expireRecords(ctx context.Context, kind expiry.Kind) error {
+	var ids []uint
+	if err := database.DB.WithContext(ctx).Table(kind.Table).
+		Where("status = ? AND expires_at <= ?", kind.ActiveStatus, time.Now()).
+		Pluck("id", &ids).Error; err != nil {
+		return fmt.Errorf("finding expired %s records: %w", kind.Label, err)
+	}
+
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// The status condition keeps a record that was renewed or closed meanwhile untouched
+		result := database.DB.Table(kind.Table).
+			Where("id = ? AND status = ?", id, kind.ActiveStatus).
+			Update("status", expiry.StatusExpired)
+		if result.Error != nil {
+			log.Printf("Error expiring %s #%d: %v", kind.Label, id, result.Error)
+			continue
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		audit.Record(audit.WorkerActor("expiry"), audit.Entry{
+			Action:     kind.TargetType + ".expire",
+			TargetType: kind.TargetType,
+			TargetID:   id,
+			FromStatus: kind.ActiveStatus,
+			ToStatus:   expiry.StatusExpired,
+			Reason:     "Not renewed before its expiry date",
+		})
+
+		switch kind.TargetType {
+		case "requested_item":
+			if err := offers.DeclineActive(id, "The request expired."); err != nil {
+				log.Printf("Error declining the active offers on expired %s #%d: %v", kind.Label, id, err)
+			}
+		case "service_request":
+			if err := proposals.DeclineOpen(id, "The request expired."); err != nil {
+				log.Printf("Error declining the open proposals on expired %s #%d: %v", kind.Label, id, err)
+			}
+		}
+	}
+
+	if len(ids) > 0 {
+		log.Printf("Expired %d %s records", len(ids), kind.Label)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"OpenEx-Backend/internal/database"
+	"OpenEx-Backend/internal/models"
+	"OpenEx-Backend/internal/scheduler"
+	"OpenEx-Backend/internal/services/moderator"
+
+	"gorm.io/gorm"
+)
+
+// Modes for moderating content as soon as it is submitted
+const (
+	SubmitModeOff   = "off"   // Wait for the auto-approver's waiting period
+	SubmitModeSync  = "sync"  // Moderate inside the create request
+	SubmitModeQueue = "queue" // Moderate in the background right after the request
+)
+
+// submission is a listing waiting in the moderation queue
+type submission struct {
+	kind string
+	id   uint
+}
+
+var (
+	submissionsMu sync.RWMutex
+	submissions   chan submission
+)
+
+// SubmitMode returns the configured MODERATION_ON_SUBMIT mode
+func SubmitMode() string {
+	switch mode := strings.ToLower(strings.TrimSpace(os.Getenv("MODERATION_ON_SUBMIT"))); mode {
+	case SubmitModeSync, SubmitModeQueue:
+		return mode
+	}
+	return SubmitModeOff
+}
+
+// startSubmissionQueue starts the workers that moderate queued submissions. They stop with
+// the scheduler; whatever is still queued then stays pending for the auto-approver.
+func startSubmissionQueue(jobs *scheduler.Scheduler) {
+	size := 100
+	if value, err := strconv.Atoi(os.Getenv("MODERATION_QUEUE_SIZE")); err == nil && value > 0 {
+		size = value
+	}
+	workers := 2
+	if value, err := strconv.Atoi(os.Getenv("MODERATION_QUEUE_WORKERS")); err == nil && value > 0 {
+		workers = value
+	}
+
+	queue := make(chan submission, size)
+	for i := 0; i < workers; i++ {
+		jobs.Go(fmt.Sprintf("moderation-queue-%d", i+1), func(ctx context.Context) {
+			for {
+				select {
+				case <-ctx.Done():
+					stopSubmissionQueue(queue)
+					return
+				case next := <-queue:
+					processSubmission(ctx, next)
+				}
+			}
+		})
+	}
+
+	submissionsMu.Lock()
+	submissions = queue
+	submissionsMu.Unlock()
+
+	log.Printf("Moderation queue started (%d workers, capacity %d)", workers, size)
+}
+
+// stopSubmissionQueue stops accepting submissions once the workers shut down
+func stopSubmissionQueue(queue chan submission) {
+	submissionsMu.Lock()
+	defer submissionsMu.Unlock()
+
+	if submissions == queue {
+		submissions = nil
+	}
+}
+
+// enqueue adds a submission to the queue. When the queue is full or not running the listing
+// simply stays pending for the auto-approver.
+func enqueue(next submission) {
+	submissionsMu.RLock()
+	queue := submissions
+	submissionsMu.RUnlock()
+
+	if queue == nil {
+		log.Printf("Moderation queue not running, %s #%d waits for the auto-approver", next.kind, next.id)
+		return
+	}
+
+	select {
+	case queue <- next:
+	default:
+		log.Printf("Moderation queue full, %s #%d waits for the auto-approver", next.kind, next.id)
+	}
+}
+
+// processSubmission moderates a queued listing if it is still waiting for moderation. The row
+// is claimed first so the auto-approver on another instance cannot moderate it at the same time.
+func processSubmission(ctx context.Context, next submission) {
+	var model interface{} = &models.Item{}
+	if next.kind == moderator.KindService {
+		model = &models.Service{}
+	}
+
+	token, claimed, err := database.Claim(ctx, model, 1, claimLease, func(db *gorm.DB) *gorm.DB {
+		return db.Where("id = ? AND status = ?", next.id, "pending")
+	})
+	if err != nil {
+		log.Printf("Error claiming %s #%d: %v", next.kind, next.id, err)
+		return
+	}
+	if claimed == 0 {
+		return // Already moderated, resubmitted, deleted or claimed elsewhere
+	}
+	defer func() {
+		if err := database.ReleaseClaim(model, token); err != nil {
+			log.Printf("Error releasing %s #%d: %v", next.kind, next.id, err)
+		}
+	}()
+
+	switch next.kind {
+	case moderator.KindItem:
+		var item models.Item
+		if err := database.DB.Preload("User").Where("claim_token = ?", token).First(&item).Error; err != nil {
+			return
+		}
+		moderateItem(&item, "submission-moderator", true)
+	case moderator.KindService:
+		var service models.Service
+		if err := database.DB.Preload("User").Where("claim_token = ?", token).First(&service).Error; err != nil {
+			return
+		}
+		moderateService(&service, "submission-moderator", true)
+	}
+}
+
+// ItemSubmitted moderates a newly created or resubmitted item according to MODERATION_ON_SUBMIT.
+// Clean items are published straight away, borderline ones go to human review and items that
+// would be rejected stay pending until the waiting period ends. In sync mode the item is
+// updated in place so the caller can return its new status.
+func ItemSubmitted(item *models.Item) {
+	switch SubmitMode() {
+	case SubmitModeSync:
+		moderateItem(item, "submission-moderator", true)
+	case SubmitModeQueue:
+		enqueue(submission{kind: moderator.KindItem, id: item.ID})
+	}
+}
+
+// ServiceSubmitted moderates a newly created or resubmitted service. See ItemSubmitted.
+func ServiceSubmitted(service *models.Service) {
+	switch SubmitMode() {
+	case SubmitModeSync:
+		moderateService(service, "submission-moderator", true)
+	case SubmitModeQueue:
+		enqueue(submission{kind: moderator.KindService, id: service.ID})
+	}
+}